@@ -0,0 +1,248 @@
+// Package barcode validates and encodes the linear barcodes printed on product labels.
+//
+// The symbology of a code is inferred from its content:
+//   - 13 digits: EAN-13, the last digit is a check digit
+//   - 12 digits: UPC-A, the last digit is a check digit
+//   - anything else: Code 128 (code set B), printable ASCII only
+//
+// EncodeAs skips the inference, for codes such as SKUs that are always printed as
+// Code 128. Encode returns the bars and spaces as modules, ready to be drawn at any scale.
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Symbologies.
+const (
+	EAN13   = "ean13"
+	UPCA    = "upca"
+	Code128 = "code128"
+)
+
+// QuietZone is the number of blank modules required on each side of the bars.
+const QuietZone = 10
+
+// ErrInvalid is wrapped by every validation error.
+var ErrInvalid = errors.New("invalid barcode")
+
+// Barcode is an encoded code. Modules holds one entry per module from left to right,
+// true for a bar and false for a space, without the quiet zones.
+type Barcode struct {
+	Type    string
+	Text    string
+	Modules []bool
+}
+
+// Detect returns the symbology code is encoded with.
+func Detect(code string) string {
+	if isDigits(code) {
+		switch len(code) {
+		case 13:
+			return EAN13
+		case 12:
+			return UPCA
+		}
+	}
+
+	return Code128
+}
+
+// Validate checks code against the rules of its inferred symbology.
+func Validate(code string) error {
+	return ValidateAs(code, Detect(code))
+}
+
+// ValidateAs checks code against the rules of the given symbology.
+func ValidateAs(code, symbology string) error {
+	switch symbology {
+	case EAN13, UPCA:
+		name, length := "EAN-13", 13
+		if symbology == UPCA {
+			name, length = "UPC-A", 12
+		}
+		if len(code) != length || !isDigits(code) {
+			return fmt.Errorf("%w: %s must be %d digits", ErrInvalid, name, length)
+		}
+		want := checkDigit(code[:len(code)-1])
+		if code[len(code)-1] != want {
+			return fmt.Errorf("%w: %s check digit must be %c", ErrInvalid, name, want)
+		}
+	case Code128:
+		if code == "" {
+			return fmt.Errorf("%w: must not be empty", ErrInvalid)
+		}
+		for _, r := range code {
+			if r < 32 || r > 126 {
+				return fmt.Errorf("%w: Code 128 allows printable ASCII characters only", ErrInvalid)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown symbology %q", ErrInvalid, symbology)
+	}
+
+	return nil
+}
+
+// Encode validates code and returns its modules in its inferred symbology.
+func Encode(code string) (Barcode, error) {
+	return EncodeAs(code, Detect(code))
+}
+
+// EncodeAs validates code and returns its modules in the given symbology.
+func EncodeAs(code, symbology string) (Barcode, error) {
+	err := ValidateAs(code, symbology)
+	if err != nil {
+		return Barcode{}, err
+	}
+
+	b := Barcode{Type: symbology, Text: code}
+	switch b.Type {
+	case EAN13:
+		b.Modules = encodeEAN13(code)
+	case UPCA:
+		// A UPC-A code is an EAN-13 code with a leading zero.
+		b.Modules = encodeEAN13("0" + code)
+	default:
+		b.Modules = encodeCode128(code)
+	}
+
+	return b, nil
+}
+
+// checkDigit returns the GTIN check digit of digits, weighting the rightmost digit by 3.
+func checkDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// eanL holds the left-hand odd parity patterns. Even parity patterns are the reversed
+// right-hand patterns, and right-hand patterns are the complement of these.
+var eanL = [10]string{
+	"0001101", "0011001", "0010011", "0111101", "0100011",
+	"0110001", "0101111", "0111011", "0110111", "0001011",
+}
+
+// eanParity selects odd (L) or even (G) parity for the left six digits; the first
+// digit of the code is carried by this choice instead of by bars of its own.
+var eanParity = [10]string{
+	"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG",
+	"LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL",
+}
+
+func encodeEAN13(code string) []bool {
+	var sb strings.Builder
+	sb.WriteString("101")
+
+	parity := eanParity[code[0]-'0']
+	for i := 1; i <= 6; i++ {
+		l := eanL[code[i]-'0']
+		if parity[i-1] == 'G' {
+			sb.WriteString(reverse(complement(l)))
+		} else {
+			sb.WriteString(l)
+		}
+	}
+
+	sb.WriteString("01010")
+	for i := 7; i <= 12; i++ {
+		sb.WriteString(complement(eanL[code[i]-'0']))
+	}
+	sb.WriteString("101")
+
+	return toModules(sb.String())
+}
+
+// code128Widths holds the bar and space widths of Code 128 values 0 to 106.
+var code128Widths = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128StartB = 104
+	code128Stop   = 106
+)
+
+func encodeCode128(code string) []bool {
+	values := []int{code128StartB}
+	sum := code128StartB
+	for i := 0; i < len(code); i++ {
+		v := int(code[i]) - 32
+		values = append(values, v)
+		sum += (i + 1) * v
+	}
+	values = append(values, sum%103, code128Stop)
+
+	var modules []bool
+	for _, v := range values {
+		bar := true
+		for _, w := range code128Widths[v] {
+			for range int(w - '0') {
+				modules = append(modules, bar)
+			}
+			bar = !bar
+		}
+	}
+
+	return modules
+}
+
+func complement(pattern string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '0' {
+			return '1'
+		}
+		return '0'
+	}, pattern)
+}
+
+func reverse(s string) string {
+	b := []byte(s)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+
+	return string(b)
+}
+
+func toModules(pattern string) []bool {
+	modules := make([]bool, len(pattern))
+	for i := range pattern {
+		modules[i] = pattern[i] == '1'
+	}
+
+	return modules
+}
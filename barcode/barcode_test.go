@@ -0,0 +1,164 @@
+package barcode_test
+
+import (
+	"errors"
+	"fendi/modul-03-task/barcode"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{code: "4006381333931", want: barcode.EAN13},
+		{code: "4006381333932", want: barcode.EAN13},
+		{code: "036000291452", want: barcode.UPCA},
+		{code: "12345", want: barcode.Code128},
+		{code: "12345678901234", want: barcode.Code128},
+		{code: "400638133393A", want: barcode.Code128},
+		{code: "ITEM-ABC-0001", want: barcode.Code128},
+		{code: "", want: barcode.Code128},
+	}
+
+	for _, tt := range tests {
+		if got := barcode.Detect(tt.code); got != tt.want {
+			t.Errorf("Detect(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		code    string
+		wantErr bool
+	}{
+		// EAN-13
+		{code: "4006381333931"},
+		{code: "5901234123457"},
+		{code: "9780201379624"},
+		{code: "4006381333932", wantErr: true},
+		{code: "5901234123450", wantErr: true},
+
+		// UPC-A
+		{code: "036000291452"},
+		{code: "012345678905"},
+		{code: "036000291453", wantErr: true},
+		{code: "012345678900", wantErr: true},
+
+		// Code 128
+		{code: "ITEM-ABC-0001"},
+		{code: "Hello World ~"},
+		{code: "12345"},
+		{code: "", wantErr: true},
+		{code: "café", wantErr: true},
+		{code: "TAB\tKEY", wantErr: true},
+		{code: "DEL\x7f", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := barcode.Validate(tt.code)
+		if tt.wantErr {
+			if !errors.Is(err, barcode.ErrInvalid) {
+				t.Errorf("Validate(%q) error = %v, want ErrInvalid", tt.code, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Validate(%q) error = %v, want nil", tt.code, err)
+		}
+	}
+}
+
+func TestEncodeAs(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		symbology string
+		want      string
+	}{
+		{
+			// First digit 4 selects the LGLLGG parity for the left half.
+			name:      "EAN-13",
+			code:      "4006381333931",
+			symbology: barcode.EAN13,
+			want: "101" +
+				"0001101" + "0100111" + "0101111" + "0111101" + "0001001" + "0110011" +
+				"01010" +
+				"1000010" + "1000010" + "1000010" + "1110100" + "1000010" + "1100110" +
+				"101",
+		},
+		{
+			name:      "UPC-A",
+			code:      "036000291452",
+			symbology: barcode.UPCA,
+			want: "101" +
+				"0001101" + "0111101" + "0101111" + "0001101" + "0001101" + "0001101" +
+				"01010" +
+				"1101100" + "1110100" + "1100110" + "1011100" + "1001110" + "1101100" +
+				"101",
+		},
+		{
+			// Start B, P, J, J, 1, 2, 3, C, check value 55 and stop.
+			name:      "Code 128",
+			code:      "PJJ123C",
+			symbology: barcode.Code128,
+			want: "11010010000" +
+				"11101110110" + "10110111000" + "10110111000" + "10011100110" + "11001110010" + "11001011100" + "10001000110" +
+				"11101000110" +
+				"1100011101011",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := barcode.EncodeAs(tt.code, tt.symbology)
+			if err != nil {
+				t.Fatalf("EncodeAs(%q, %q) error = %v", tt.code, tt.symbology, err)
+			}
+			if b.Type != tt.symbology || b.Text != tt.code {
+				t.Errorf("EncodeAs(%q, %q) = %q %q, want %q %q", tt.code, tt.symbology, b.Type, b.Text, tt.symbology, tt.code)
+			}
+			if got := modules(b); got != tt.want {
+				t.Errorf("EncodeAs(%q, %q) modules\n got %s\nwant %s", tt.code, tt.symbology, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeAsRejectsInvalidCodes(t *testing.T) {
+	tests := []struct {
+		code      string
+		symbology string
+	}{
+		{code: "4006381333932", symbology: barcode.EAN13},
+		{code: "036000291452", symbology: barcode.EAN13},
+		{code: "4006381333931", symbology: barcode.UPCA},
+		{code: "03600029145A", symbology: barcode.UPCA},
+		{code: "", symbology: barcode.Code128},
+		{code: "café", symbology: barcode.Code128},
+		{code: "ITEM-1", symbology: "qr"},
+	}
+
+	for _, tt := range tests {
+		_, err := barcode.EncodeAs(tt.code, tt.symbology)
+		if !errors.Is(err, barcode.ErrInvalid) {
+			t.Errorf("EncodeAs(%q, %q) error = %v, want ErrInvalid", tt.code, tt.symbology, err)
+		}
+	}
+}
+
+// modules renders the bars of b as 1 and the spaces as 0.
+func modules(b barcode.Barcode) string {
+	var sb strings.Builder
+	for _, bar := range b.Modules {
+		if bar {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+
+	return sb.String()
+}
@@ -3,4 +3,13 @@ package config
 type Config struct {
 	AppPort string `mapstructure:"APP_PORT"`
 	DBConn  string `mapstructure:"DB_CONN"`
+
+	CheckoutStrictStock bool `mapstructure:"CHECKOUT_STRICT_STOCK"`
+
+	SKUPattern    string `mapstructure:"SKU_PATTERN"`
+	SKUCheckDigit bool   `mapstructure:"SKU_CHECK_DIGIT"`
+
+	StoreTimezone string `mapstructure:"STORE_TIMEZONE"`
+
+	OwnerAPIKey string `mapstructure:"OWNER_API_KEY"`
 }
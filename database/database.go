@@ -2,20 +2,106 @@ package database
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
 )
 
-func InitDB(connStr string) (db *sql.DB, err error) {
-	db, err = sql.Open("postgres", connStr)
+// sqliteConstraintUnique is SQLite's extended result code SQLITE_CONSTRAINT_UNIQUE.
+const sqliteConstraintUnique = 2067
+
+// Dialect identifies the SQL flavour spoken by the connected database.
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// ILike returns a case-insensitive "contains" condition comparing column with the given placeholder.
+func (d Dialect) ILike(column, placeholder string) string {
+	if d == SQLite {
+		// LIKE is case-insensitive for ASCII characters in SQLite.
+		return fmt.Sprintf("%s LIKE '%%' || %s || '%%'", column, placeholder)
+	}
+
+	return fmt.Sprintf("%s ILIKE '%%' || %s || '%%'", column, placeholder)
+}
+
+// Now returns the SQL expression for the current timestamp.
+func (d Dialect) Now() string {
+	if d == SQLite {
+		return "CURRENT_TIMESTAMP"
+	}
+
+	return "NOW()"
+}
+
+// ForUpdate returns the row locking clause appended to a SELECT inside a transaction.
+// SQLite has no row locks; its transactions already hold the database write lock.
+func (d Dialect) ForUpdate() string {
+	if d == SQLite {
+		return ""
+	}
+
+	return " FOR UPDATE"
+}
+
+// IsUniqueViolation reports whether err was caused by a UNIQUE constraint. The error
+// text names the constraint or column, so callers can tell which value clashed.
+func (d Dialect) IsUniqueViolation(err error) bool {
+	if d == SQLite {
+		var sqliteErr *sqlite.Error
+		return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqliteConstraintUnique
+	}
+
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// ParseConn resolves the dialect and driver data source name from DB_CONN.
+// sqlite://<path> and sqlite:<path> select SQLite, anything else is handed to PostgreSQL.
+func ParseConn(connStr string) (Dialect, string) {
+	if path, ok := strings.CutPrefix(connStr, "sqlite://"); ok {
+		return SQLite, sqliteDSN(path)
+	}
+	if path, ok := strings.CutPrefix(connStr, "sqlite:"); ok {
+		return SQLite, sqliteDSN(path)
+	}
+
+	return Postgres, connStr
+}
+
+// sqliteDSN builds the SQLite data source name with the pragmas the repositories rely on.
+func sqliteDSN(path string) string {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+
+	// _txlock=immediate takes the write lock at BEGIN so concurrent checkouts queue
+	// up instead of failing with SQLITE_BUSY, and _time_format keeps timestamps
+	// comparable as text.
+	return "file:" + path + separator +
+		"_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)" +
+		"&_txlock=immediate&_time_format=sqlite"
+}
+
+func InitDB(connStr string) (db *sql.DB, dialect Dialect, err error) {
+	dialect, dsn := ParseConn(connStr)
+
+	db, err = sql.Open(string(dialect), dsn)
 	if err != nil {
-		return nil, err
+		return nil, dialect, err
 	}
 
 	err = db.Ping()
 	if err != nil {
-		return nil, err
+		return nil, dialect, err
 	}
 
 	db.SetMaxOpenConns(25)
@@ -23,5 +109,5 @@ func InitDB(connStr string) (db *sql.DB, err error) {
 
 	log.Println("Database connected successfully.")
 
-	return db, nil
+	return db, dialect, nil
 }
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS transactions (
+    id SERIAL PRIMARY KEY,
+    uuid VARCHAR(255) UNIQUE NOT NULL,
+    total_amount DECIMAL(10, 2) NOT NULL,
+    purchased_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
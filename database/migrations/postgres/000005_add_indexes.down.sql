@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_transaction_details_product_id;
+DROP INDEX IF EXISTS idx_transaction_details_transaction_id;
+DROP INDEX IF EXISTS idx_transactions_purchased_at;
+DROP INDEX IF EXISTS idx_products_deleted_at;
+DROP INDEX IF EXISTS idx_products_category_id;
+DROP INDEX IF EXISTS idx_categories_deleted_at;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS refunds (
+    id SERIAL PRIMARY KEY,
+    uuid VARCHAR(255) UNIQUE NOT NULL,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+    type VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    total_amount DECIMAL(10, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS refund_details (
+    id SERIAL PRIMARY KEY,
+    refund_id INTEGER NOT NULL REFERENCES refunds(id),
+    transaction_detail_id INTEGER NOT NULL REFERENCES transaction_details(id),
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    name VARCHAR(255) NOT NULL,
+    price DECIMAL(10, 2) NOT NULL,
+    quantity INTEGER NOT NULL,
+    subtotal DECIMAL(10, 2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_transaction_id ON refunds (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_created_at ON refunds (created_at);
+CREATE INDEX IF NOT EXISTS idx_refund_details_refund_id ON refund_details (refund_id);
+CREATE INDEX IF NOT EXISTS idx_refund_details_transaction_detail_id ON refund_details (transaction_detail_id);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_products_store_barcode;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_barcode ON products (barcode);
+
+DROP INDEX IF EXISTS idx_transactions_store_id;
+DROP INDEX IF EXISTS idx_products_store_id;
+DROP INDEX IF EXISTS idx_categories_store_id;
+
+ALTER TABLE users DROP COLUMN store_id;
+ALTER TABLE transactions DROP COLUMN store_id;
+ALTER TABLE products DROP COLUMN store_id;
+ALTER TABLE categories DROP COLUMN store_id;
+
+DROP TABLE IF EXISTS stores;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS stores (
+    id SERIAL PRIMARY KEY,
+    uuid VARCHAR(255) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Everything recorded before stores existed belongs to the first store.
+INSERT INTO stores (id, uuid, name) VALUES (1, 'b3f1c2a4-6d8e-4f3a-9c5b-1e2d3f4a5b6c', 'Main store');
+SELECT setval(pg_get_serial_sequence('stores', 'id'), 1);
+
+ALTER TABLE categories ADD COLUMN store_id INTEGER NOT NULL DEFAULT 1 REFERENCES stores(id);
+ALTER TABLE categories ALTER COLUMN store_id DROP DEFAULT;
+ALTER TABLE products ADD COLUMN store_id INTEGER NOT NULL DEFAULT 1 REFERENCES stores(id);
+ALTER TABLE products ALTER COLUMN store_id DROP DEFAULT;
+ALTER TABLE transactions ADD COLUMN store_id INTEGER NOT NULL DEFAULT 1 REFERENCES stores(id);
+ALTER TABLE transactions ALTER COLUMN store_id DROP DEFAULT;
+
+-- Owners use every store; managers and cashiers work in one.
+ALTER TABLE users ADD COLUMN store_id INTEGER REFERENCES stores(id);
+UPDATE users SET store_id = 1 WHERE role <> 'owner';
+
+CREATE INDEX IF NOT EXISTS idx_categories_store_id ON categories (store_id);
+CREATE INDEX IF NOT EXISTS idx_products_store_id ON products (store_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_store_id ON transactions (store_id, purchased_at);
+
+-- The same goods carry the same barcode in every store, so barcodes are unique per
+-- store. SKUs stay unique across stores.
+DROP INDEX IF EXISTS idx_products_barcode;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_store_barcode ON products (store_id, barcode);
@@ -0,0 +1,20 @@
+-- changes maps each changed field to its value before and after, as JSON. Actors are
+-- kept by UUID, name and role rather than a reference: the OWNER_API_KEY has no user
+-- row, and entries must outlive deleted users.
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id SERIAL PRIMARY KEY,
+    uuid VARCHAR(255) UNIQUE NOT NULL,
+    store_id INTEGER NOT NULL REFERENCES stores(id),
+    actor_id VARCHAR(255) NOT NULL,
+    actor_name VARCHAR(255) NOT NULL,
+    actor_role VARCHAR(20) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    changes TEXT NOT NULL,
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_entries_store_created ON audit_entries (store_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_entity ON audit_entries (entity_type, entity_id);
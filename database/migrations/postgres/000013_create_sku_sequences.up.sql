@@ -0,0 +1,6 @@
+-- last_value is the last {SEQ} number handed out for SKUs starting with prefix. Rows
+-- are created on first use, seeded from the products already using the prefix.
+CREATE TABLE IF NOT EXISTS sku_sequences (
+    prefix VARCHAR(255) PRIMARY KEY,
+    last_value BIGINT NOT NULL
+);
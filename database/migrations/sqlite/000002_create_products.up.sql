@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS products (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid VARCHAR(255) UNIQUE NOT NULL,
+    sku VARCHAR(255) UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    stock INTEGER,
+    price DECIMAL(10, 2),
+    category_id INTEGER REFERENCES categories(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    type VARCHAR(20) NOT NULL,
+    delta INTEGER NOT NULL,
+    balance INTEGER NOT NULL,
+    reference VARCHAR(255) NOT NULL,
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_id ON stock_movements (product_id, id);
+
+-- Opening balance for products that already track stock.
+INSERT INTO stock_movements (product_id, type, delta, balance, reference)
+SELECT id, 'receiving', stock, stock, 'opening balance'
+FROM products
+WHERE stock IS NOT NULL;
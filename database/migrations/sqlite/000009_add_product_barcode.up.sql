@@ -0,0 +1,3 @@
+ALTER TABLE products ADD COLUMN barcode VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_barcode ON products (barcode);
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+)
+
+// csvWriter writes tables one after another. The first table starts on the first
+// line; every later one follows a blank line and a line with its name.
+type csvWriter struct {
+	w      *csv.Writer
+	loc    *time.Location
+	tables int
+}
+
+func newCSVWriter(w io.Writer, loc *time.Location) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w), loc: loc}
+}
+
+func (c *csvWriter) Table(name string, columns ...string) error {
+	if c.tables > 0 {
+		err := c.w.WriteAll([][]string{{}, {name}})
+		if err != nil {
+			return err
+		}
+	}
+	c.tables++
+
+	return c.Row(anySlice(columns)...)
+}
+
+func (c *csvWriter) Row(values ...any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		s := text(v, c.loc)
+		// Spreadsheets run text starting with these characters as a formula, so a
+		// product named "=SUM(...)" must not turn into one.
+		if _, ok := v.(string); ok && s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+			s = "'" + s
+		}
+		record[i] = s
+	}
+
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func anySlice(s []string) []any {
+	values := make([]any, len(s))
+	for i, v := range s {
+		values[i] = v
+	}
+
+	return values
+}
@@ -0,0 +1,95 @@
+// Package export writes tables of report and transaction data as files for
+// spreadsheets and accounting tools: streamed CSV and XLSX, and printable PDF
+// documents. Everything is produced in-process without external tools.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"fendi/modul-03-task/model"
+)
+
+// File formats.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatPDF  = "pdf"
+)
+
+// contentTypes maps each format to its media type.
+var contentTypes = map[string]string{
+	FormatCSV:  "text/csv; charset=utf-8",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatPDF:  "application/pdf",
+}
+
+// ErrUnsupportedFormat is returned for a format a writer cannot produce.
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// ContentType returns the media type of format.
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// FormatOf returns the format whose media type is mediaType, ignoring parameters
+// such as charset, or "" when it is not an export format.
+func FormatOf(mediaType string) string {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for format, ct := range contentTypes {
+		base, _, _ := strings.Cut(ct, ";")
+		if base == mediaType {
+			return format
+		}
+	}
+
+	return ""
+}
+
+// Writer streams tables to a file. Cells are string, int, int64, float64,
+// model.Money or time.Time values; times are written in the writer's location.
+type Writer interface {
+	// Table starts a new table with a header row of columns. In a workbook every
+	// table is a sheet named name.
+	Table(name string, columns ...string) error
+	// Row appends a row to the current table.
+	Row(values ...any) error
+	// Close finishes the file. It does not close the underlying writer.
+	Close() error
+}
+
+// NewWriter returns a Writer for the CSV or XLSX format writing times in loc.
+func NewWriter(w io.Writer, format string, loc *time.Location) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, loc), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, loc), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// timeLayout is how times are written as text.
+const timeLayout = "2006-01-02 15:04:05"
+
+// text formats a cell value as text. Money is a plain decimal so it reads back as
+// a number.
+func text(v any, loc *time.Location) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case model.Money:
+		return v.String()
+	case time.Time:
+		return v.In(loc).Format(timeLayout)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,266 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page geometry in points.
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	pageMargin   = 50.0
+	contentWidth = pageWidth - 2*pageMargin
+)
+
+// Fonts of a Document. They are standard PDF fonts, so readers have them built in
+// and nothing is embedded.
+const (
+	fontRegular = "F1" // Helvetica
+	fontBold    = "F2" // Helvetica-Bold
+	fontMono    = "F3" // Courier
+	fontMonoB   = "F4" // Courier-Bold
+)
+
+// Font sizes and line heights of a Document.
+const (
+	titleSize  = 16.0
+	textSize   = 10.0
+	tableSize  = 9.0
+	lineHeight = 1.4
+)
+
+// courierAdvance is the width of every Courier glyph, per point of font size.
+// Tables are set in Courier so columns can be measured and aligned by counting
+// characters.
+const courierAdvance = 0.6
+
+// Column describes a table column of a Document.
+type Column struct {
+	Title string
+	// Width is the column width in characters.
+	Width int
+	// Right aligns the column to the right, as for amounts.
+	Right bool
+}
+
+// Document lays out a printable A4 PDF from top to bottom, starting a new page
+// when one is full. Text is limited to the Windows-1252 character set of the
+// standard fonts; other characters print as "?".
+type Document struct {
+	// Footer is printed at the bottom of every page with the page number.
+	Footer string
+
+	pages []*bytes.Buffer
+	y     float64
+}
+
+// NewDocument starts a document with one empty page.
+func NewDocument() *Document {
+	d := &Document{}
+	d.newPage()
+	return d
+}
+
+func (d *Document) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - pageMargin
+}
+
+// page returns the current page, after starting a new one when less than height
+// points are left.
+func (d *Document) page(height float64) *bytes.Buffer {
+	if d.y-height < pageMargin {
+		d.newPage()
+	}
+	d.y -= height
+
+	return d.pages[len(d.pages)-1]
+}
+
+// Title writes a line of large bold text.
+func (d *Document) Title(s string) {
+	d.text(fontBold, titleSize, pageMargin, s)
+}
+
+// Heading writes a line of bold text, keeping it on the page of the next lines.
+func (d *Document) Heading(s string) {
+	if d.y-4*textSize*lineHeight < pageMargin {
+		d.newPage()
+	}
+	d.text(fontBold, textSize, pageMargin, s)
+}
+
+// Text writes a line of regular text.
+func (d *Document) Text(s string) {
+	d.text(fontRegular, textSize, pageMargin, s)
+}
+
+// Space leaves a blank line.
+func (d *Document) Space() {
+	d.page(textSize * lineHeight)
+}
+
+// Rule draws a horizontal line across the page.
+func (d *Document) Rule() {
+	p := d.page(textSize * lineHeight / 2)
+	fmt.Fprintf(p, "0.5 w %.2f %.2f m %.2f %.2f l S\n", pageMargin, d.y, pageWidth-pageMargin, d.y)
+}
+
+func (d *Document) text(font string, size, x float64, s string) {
+	p := d.page(size * lineHeight)
+	writeText(p, font, size, x, d.y, s)
+}
+
+// Table writes a table with a bold header row. Cells longer than their column are
+// cut short; a header is repeated on every page the table runs onto.
+func (d *Document) Table(columns []Column, rows [][]string) {
+	gap := 2
+	width := 0
+	for _, c := range columns {
+		width += c.Width + gap
+	}
+	// Scale the font down when the columns are wider than the page.
+	size := min(tableSize, contentWidth/(float64(width-gap)*courierAdvance))
+	height := size * lineHeight
+
+	line := func(font string, cells []string) {
+		var sb strings.Builder
+		for i, c := range columns {
+			cell := ""
+			if i < len(cells) {
+				cell = fit(cells[i], c.Width)
+			}
+			pad := strings.Repeat(" ", c.Width-len([]rune(cell)))
+			if c.Right {
+				sb.WriteString(pad + cell)
+			} else {
+				sb.WriteString(cell + pad)
+			}
+			if i < len(columns)-1 {
+				sb.WriteString(strings.Repeat(" ", gap))
+			}
+		}
+		writeText(d.pages[len(d.pages)-1], font, size, pageMargin, d.y, strings.TrimRight(sb.String(), " "))
+	}
+	titles := make([]string, len(columns))
+	for i, c := range columns {
+		titles[i] = c.Title
+	}
+	header := func() {
+		d.page(height)
+		line(fontMonoB, titles)
+	}
+
+	// Keep the header with at least one row.
+	if d.y-2*height < pageMargin {
+		d.newPage()
+	}
+	header()
+	for _, row := range rows {
+		pages := len(d.pages)
+		d.page(height)
+		if len(d.pages) != pages {
+			line(fontMonoB, titles)
+			d.page(height)
+		}
+		line(fontMono, row)
+	}
+}
+
+// fit cuts s to at most n characters, ending in "~" when it was cut.
+func fit(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n < 1 {
+		return ""
+	}
+
+	return string(r[:n-1]) + "~"
+}
+
+// writeText writes a text-showing operation for s at x, y.
+func writeText(w *bytes.Buffer, font string, size, x, y float64, s string) {
+	fmt.Fprintf(w, "BT /%s %.1f Tf %.2f %.2f Td (", font, size, x, y)
+	for _, r := range s {
+		b := winAnsi(r)
+		if b == '(' || b == ')' || b == '\\' {
+			w.WriteByte('\\')
+		}
+		w.WriteByte(b)
+	}
+	w.WriteString(") Tj ET\n")
+}
+
+// winAnsiExtra maps the characters of Windows-1252 outside Latin-1 that shop names
+// and addresses are likely to use.
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// winAnsi encodes r for the WinAnsiEncoding of the standard fonts.
+func winAnsi(r rune) byte {
+	switch {
+	case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+		return byte(r)
+	case r == '\t':
+		return ' '
+	}
+	if b, ok := winAnsiExtra[r]; ok {
+		return b
+	}
+
+	return '?'
+}
+
+// WriteTo writes the document as a PDF file.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1 to 6 are the catalog, the page tree and the fonts; every page then
+	// takes two, the page and its content stream.
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 7+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %.0f %.0f] >>",
+		strings.Join(kids, " "), len(d.pages), pageWidth, pageHeight))
+	for _, name := range []string{"Helvetica", "Helvetica-Bold", "Courier", "Courier-Bold"} {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+	}
+
+	for i, p := range d.pages {
+		content := bytes.NewBuffer(bytes.Clone(p.Bytes()))
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(d.pages))
+		if d.Footer != "" {
+			writeText(content, fontRegular, 8, pageMargin, pageMargin/2, d.Footer)
+		}
+		// Right-aligned in Courier, whose width is known.
+		writeText(content, fontMono, 8, pageWidth-pageMargin-float64(len(footer))*8*courierAdvance, pageMargin/2, footer)
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Contents %d 0 R /Resources << /Font << "+
+			"/F1 3 0 R /F2 4 0 R /F3 5 0 R /F4 6 0 R >> >> >>", 8+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
@@ -0,0 +1,237 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"fendi/modul-03-task/model"
+)
+
+// Cell styles of styles.xml, by index.
+const (
+	styleDefault = iota
+	styleHeader
+	styleMoney
+	styleTime
+)
+
+// xlsxWriter streams an Office Open XML workbook. Sheets are written to the zip
+// archive as their rows arrive; the parts that list the sheets are written on
+// Close. Text goes in inline strings, so no shared string table has to be held
+// in memory.
+type xlsxWriter struct {
+	zip    *zip.Writer
+	sheet  *bufio.Writer
+	loc    *time.Location
+	sheets []string
+	row    int
+	err    error
+}
+
+func newXLSXWriter(w io.Writer, loc *time.Location) *xlsxWriter {
+	return &xlsxWriter{zip: zip.NewWriter(w), loc: loc}
+}
+
+func (x *xlsxWriter) Table(name string, columns ...string) error {
+	if x.err != nil {
+		return x.err
+	}
+	x.endSheet()
+
+	x.sheets = append(x.sheets, sheetName(name, len(x.sheets)+1))
+	part, err := x.zip.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(x.sheets)))
+	if err != nil {
+		x.err = err
+		return err
+	}
+	x.sheet = bufio.NewWriter(part)
+	x.row = 0
+	x.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+		`<sheetData>`)
+
+	x.writeRow(anySlice(columns), styleHeader)
+	return x.err
+}
+
+func (x *xlsxWriter) Row(values ...any) error {
+	if x.err != nil {
+		return x.err
+	}
+	if x.sheet == nil {
+		return fmt.Errorf("export: Row called before Table")
+	}
+
+	x.writeRow(values, styleDefault)
+	return x.err
+}
+
+// writeRow writes one row element. style applies to every text cell.
+func (x *xlsxWriter) writeRow(values []any, style int) {
+	x.row++
+	fmt.Fprintf(x.sheet, `<row r="%d">`, x.row)
+	for i, v := range values {
+		ref := columnName(i) + strconv.Itoa(x.row)
+		switch v := v.(type) {
+		case nil:
+		case int:
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%d</v></c>`, ref, v)
+		case int64:
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%d</v></c>`, ref, v)
+		case float64:
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'f', -1, 64))
+		case model.Money:
+			fmt.Fprintf(x.sheet, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleMoney, v.String())
+		case time.Time:
+			fmt.Fprintf(x.sheet, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleTime, strconv.FormatFloat(serialDate(v.In(x.loc)), 'f', -1, 64))
+		default:
+			fmt.Fprintf(x.sheet, `<c r="%s" t="inlineStr" s="%d"><is><t xml:space="preserve">`, ref, style)
+			xml.EscapeText(x.sheet, []byte(text(v, x.loc)))
+			x.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	// A bufio.Writer keeps failing after its first error, so the last write
+	// reports any error of the row.
+	_, x.err = x.sheet.WriteString(`</row>`)
+}
+
+// endSheet closes the sheet being written, if any.
+func (x *xlsxWriter) endSheet() {
+	if x.sheet == nil {
+		return
+	}
+
+	x.sheet.WriteString(`</sheetData></worksheet>`)
+	err := x.sheet.Flush()
+	if err != nil && x.err == nil {
+		x.err = err
+	}
+	x.sheet = nil
+}
+
+func (x *xlsxWriter) Close() error {
+	if x.err != nil {
+		return x.err
+	}
+	if len(x.sheets) == 0 {
+		// A workbook needs at least one sheet.
+		x.Table("Sheet1")
+	}
+	x.endSheet()
+	if x.err != nil {
+		return x.err
+	}
+
+	var contentTypes, workbook, rels strings.Builder
+	contentTypes.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i, name := range x.sheets {
+		n := i + 1
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeAttr(name), n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(x.sheets)+1)
+	rels.WriteString(`</Relationships>`)
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", contentTypes.String()},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", rels.String()},
+		{"xl/styles.xml", stylesXML},
+	}
+	for _, p := range parts {
+		w, err := x.zip.Create(p.name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, p.body)
+		if err != nil {
+			return err
+		}
+	}
+
+	return x.zip.Close()
+}
+
+// stylesXML defines the cell styles in the order of the style constants: default,
+// bold header, Rupiah amounts and date-times.
+const stylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2">` +
+	`<numFmt numFmtId="164" formatCode="&quot;Rp &quot;#,##0.00"/>` +
+	`<numFmt numFmtId="165" formatCode="yyyy-mm-dd hh:mm:ss"/>` +
+	`</numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`</styleSheet>`
+
+// excelEpoch is day zero of spreadsheet serial dates.
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// serialDate converts the wall clock time of t to a spreadsheet serial date: days
+// since excelEpoch, the time of day as the fraction.
+func serialDate(t time.Time) float64 {
+	y, m, d := t.Date()
+	wall := time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	return wall.Sub(excelEpoch).Seconds() / 86400
+}
+
+// columnName returns the letters of the zero-based column i: A, B, ..., Z, AA.
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+
+	return name
+}
+
+// sheetName makes name a valid sheet name: at most 31 characters without the ones
+// spreadsheets reserve. An empty name becomes "Sheet" and the sheet number.
+func sheetName(name string, n int) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	if name == "" {
+		name = fmt.Sprintf("Sheet%d", n)
+	}
+
+	return name
+}
+
+func escapeAttr(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"encoding/json"
+	"fendi/modul-03-task/service"
+	"fendi/modul-03-task/transport"
+	"fmt"
+	"net/http"
+)
+
+type AuditHandler struct {
+	service *service.AuditService
+}
+
+func NewAuditHandler(service *service.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+func (h *AuditHandler) GetAuditEntries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := transport.AuditListRequest{
+		Entity:    query.Get("entity"),
+		EntityID:  query.Get("entity_id"),
+		Action:    query.Get("action"),
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+		Cursor:    query.Get("cursor"),
+		Limit:     query.Get("limit"),
+	}
+
+	res, err := h.service.GetAuditEntries(r.Context(), req)
+	if err != nil {
+		fmt.Print("handler.audit.GetAuditEntries() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
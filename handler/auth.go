@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"fendi/modul-03-task/service"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type AuthHandler struct {
+	service *service.AuthService
+}
+
+func NewAuthHandler(service *service.AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+// storeHeader names the store an owner works in.
+const storeHeader = "X-Store-ID"
+
+// Require wraps next so it only runs for callers whose role may do what role may
+// do. The API key is read from an "Authorization: Bearer" or X-API-Key header. The
+// store the request works in is resolved from the caller and the X-Store-ID header.
+func (h *AuthHandler) Require(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, err := h.service.Authenticate(r.Context(), apiKey(r))
+		if err != nil {
+			fmt.Print("handler.auth.Require() Error: ", err.Error())
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+			writeError(w, err)
+			return
+		}
+
+		err = h.service.Authorize(principal, role)
+		if err != nil {
+			fmt.Printf("handler.auth.Require() Error: %s %s by user %s: %v", r.Method, r.URL.Path, principal.User.UUID, err)
+			writeError(w, err)
+			return
+		}
+
+		store, err := h.service.ResolveStore(r.Context(), principal, strings.TrimSpace(r.Header.Get(storeHeader)))
+		if err != nil {
+			fmt.Printf("handler.auth.Require() Error: %s %s by user %s: %v", r.Method, r.URL.Path, principal.User.UUID, err)
+			writeError(w, err)
+			return
+		}
+
+		ctx := service.WithPrincipal(r.Context(), principal)
+		if store != nil {
+			ctx = service.WithStore(ctx, *store)
+		}
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// apiKey returns the API key a request carries, or "".
+func apiKey(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+func (h *AuthHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	principal, _ := service.PrincipalFromContext(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.service.Me(r.Context(), principal))
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fendi/modul-03-task/service"
 	"fendi/modul-03-task/transport"
+	"fendi/modul-03-task/validation"
 	"fmt"
 	"net/http"
 )
@@ -16,26 +17,20 @@ func NewCategoryHandler(service *service.CategoryService) *CategoryHandler {
 	return &CategoryHandler{service: service}
 }
 
-func (h *CategoryHandler) HandleCategory(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodGet {
-		h.GetAllCategory(w, r)
-		return
-	}
-	if r.Method == http.MethodPost {
-		h.CreateCategory(w, r)
-		return
-	}
-
-	http.NotFound(w, r)
-}
-
 func (h *CategoryHandler) GetAllCategory(w http.ResponseWriter, r *http.Request) {
-	keyword := r.URL.Query().Get("search")
+	query := r.URL.Query()
+	req := transport.CategoryListRequest{
+		Search: query.Get("search"),
+		Sort:   query.Get("sort"),
+		Page:   query.Get("page"),
+		Limit:  query.Get("limit"),
+		Cursor: query.Get("cursor"),
+	}
 
-	res, err := h.service.GetAllCategory(r.Context(), keyword)
+	res, err := h.service.GetAllCategory(r.Context(), req)
 	if err != nil {
 		fmt.Print("handler.category.GetAllCategory() Error: ", err.Error())
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -49,14 +44,21 @@ func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request)
 	err := json.NewDecoder(r.Body).Decode(&categoryReq)
 	if err != nil {
 		fmt.Print("handler.category.CreateCategory() Decode Error: ", err.Error())
-		http.Error(w, "Invalid Request Body", http.StatusBadRequest)
+		writeError(w, errInvalidBody)
+		return
+	}
+
+	err = validation.Validate(categoryReq)
+	if err != nil {
+		fmt.Print("handler.category.CreateCategory() Validation Error: ", err.Error())
+		writeError(w, err)
 		return
 	}
 
 	res, err := h.service.CreateCategory(r.Context(), categoryReq)
 	if err != nil {
 		fmt.Print("handler.category.CreateCategory() Error: ", err.Error())
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -65,40 +67,13 @@ func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(res)
 }
 
-func (h *CategoryHandler) HandleCategoryItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodGet {
-		h.GetCategoryByUUID(w, r)
-		return
-	}
-	if r.Method == http.MethodPut {
-		h.UpdateCategory(w, r)
-		return
-	}
-	if r.Method == http.MethodDelete {
-		h.DeleteCategory(w, r)
-		return
-	}
-
-	http.NotFound(w, r)
-}
-
 func (h *CategoryHandler) GetCategoryByUUID(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/categories/"):]
-	if idStr == "" {
-		fmt.Print("handler.category.GetCategoryByUUID() Error: ID is empty")
-		http.Error(w, "Not Found", http.StatusNotFound)
-		return
-	}
+	idStr := r.PathValue("uuid")
 
 	res, err := h.service.GetCategoryByUUID(r.Context(), idStr)
 	if err != nil {
 		fmt.Print("handler.category.GetCategoryByUUID() Error: ", err.Error())
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-	if res.ID == "" {
-		fmt.Print("handler.category.GetCategoryByUUID() Error: category not found")
-		http.Error(w, "Not Found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 
@@ -108,31 +83,27 @@ func (h *CategoryHandler) GetCategoryByUUID(w http.ResponseWriter, r *http.Reque
 }
 
 func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/categories/"):]
-	if idStr == "" {
-		fmt.Print("handler.category.UpdateCategory() Error: ID is empty")
-		http.Error(w, "Not Found", http.StatusNotFound)
-		return
-	}
+	idStr := r.PathValue("uuid")
 
 	var categoryReq transport.CategoryRequest
 	err := json.NewDecoder(r.Body).Decode(&categoryReq)
 	if err != nil {
 		fmt.Print("handler.category.UpdateCategory() Decode Error: ", err.Error())
-		http.Error(w, "Invalid Request Body", http.StatusBadRequest)
+		writeError(w, errInvalidBody)
 		return
 	}
 
-	res, err := h.service.UpdateCategory(r.Context(), idStr, categoryReq)
+	err = validation.Validate(categoryReq)
 	if err != nil {
-		if err.Error() == "category not found" {
-			fmt.Print("handler.category.UpdateCategory() Error: category not found")
-			http.Error(w, "Bad Request: Category not found", http.StatusBadRequest)
-			return
-		}
+		fmt.Print("handler.category.UpdateCategory() Validation Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
 
+	res, err := h.service.UpdateCategory(r.Context(), idStr, categoryReq)
+	if err != nil {
 		fmt.Print("handler.category.UpdateCategory() Error: ", err.Error())
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -142,17 +113,12 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/categories/"):]
-	if idStr == "" {
-		fmt.Print("handler.category.DeleteCategory() Error: ID is empty")
-		http.Error(w, "Not Found", http.StatusNotFound)
-		return
-	}
+	idStr := r.PathValue("uuid")
 
 	err := h.service.DeleteCategory(r.Context(), idStr)
 	if err != nil {
 		fmt.Print("handler.category.DeleteCategory() Error: ", err.Error())
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -163,3 +129,37 @@ func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request)
 		Status: "OK",
 	})
 }
+
+func (h *CategoryHandler) GetDeletedCategories(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := transport.TrashListRequest{
+		Cursor: query.Get("cursor"),
+		Limit:  query.Get("limit"),
+	}
+
+	res, err := h.service.GetDeletedCategories(r.Context(), req)
+	if err != nil {
+		fmt.Print("handler.category.GetDeletedCategories() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *CategoryHandler) RestoreCategory(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	res, err := h.service.RestoreCategory(r.Context(), idStr)
+	if err != nil {
+		fmt.Print("handler.category.RestoreCategory() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
@@ -1,28 +1,80 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fendi/modul-03-task/service"
 	"fendi/modul-03-task/transport"
+	"fendi/modul-03-task/validation"
 	"fmt"
 	"net/http"
 )
 
+// checkoutIdempotencyScope namespaces Idempotency-Key values used on POST /checkouts.
+// The store UUID is appended, so every store has its own keys.
+const checkoutIdempotencyScope = "POST /checkouts"
+
 type CheckoutHandler struct {
-	service *service.CheckoutService
+	service     *service.CheckoutService
+	idempotency *service.IdempotencyService
 }
 
-func NewCheckoutHandler(service *service.CheckoutService) *CheckoutHandler {
-	return &CheckoutHandler{service: service}
+func NewCheckoutHandler(service *service.CheckoutService, idempotency *service.IdempotencyService) *CheckoutHandler {
+	return &CheckoutHandler{service: service, idempotency: idempotency}
 }
 
-func (h *CheckoutHandler) HandleCheckout(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodPost {
-		h.Checkout(w, r)
+func (h *CheckoutHandler) GetAllTransaction(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := transport.TransactionListRequest{
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+		TZ:        query.Get("tz"),
+		MinAmount: query.Get("min_amount"),
+		MaxAmount: query.Get("max_amount"),
+		ProductID: query.Get("product_id"),
+		Cursor:    query.Get("cursor"),
+		Limit:     query.Get("limit"),
+	}
+
+	w.Header().Add("Vary", "Accept")
+	if format := exportFormat(r); format != "" {
+		res, err := h.service.ExportTransactions(r.Context(), req, format)
+		if err != nil {
+			fmt.Print("handler.checkout.GetAllTransaction() Error: ", err.Error())
+			writeError(w, err)
+			return
+		}
+
+		writeExport(w, res, "handler.checkout.GetAllTransaction()")
 		return
 	}
 
-	http.NotFound(w, r)
+	res, err := h.service.GetAllTransaction(r.Context(), req)
+	if err != nil {
+		fmt.Print("handler.checkout.GetAllTransaction() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *CheckoutHandler) GetTransactionByUUID(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	res, err := h.service.GetTransactionByUUID(r.Context(), idStr)
+	if err != nil {
+		fmt.Print("handler.checkout.GetTransactionByUUID() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
 }
 
 func (h *CheckoutHandler) Checkout(w http.ResponseWriter, r *http.Request) {
@@ -30,19 +82,140 @@ func (h *CheckoutHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&checkoutReq)
 	if err != nil {
 		fmt.Print("handler.checkout.Checkout() Decode Error: ", err.Error())
-		http.Error(w, "Invalid Request Body", http.StatusBadRequest)
+		writeError(w, errInvalidBody)
+		return
+	}
+
+	err = validation.Validate(checkoutReq)
+	if err != nil {
+		fmt.Print("handler.checkout.Checkout() Validation Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" {
+		h.createCheckout(w, r, checkoutReq)
+		return
+	}
+
+	fingerprint, err := service.Fingerprint(checkoutReq)
+	if err != nil {
+		fmt.Print("handler.checkout.Checkout() Fingerprint Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	store, ok := service.StoreFromContext(r.Context())
+	if !ok {
+		writeError(w, service.ErrStoreRequired)
+		return
+	}
+	scope := checkoutIdempotencyScope + " " + store.UUID
+
+	replay, err := h.idempotency.Begin(r.Context(), scope, key, fingerprint)
+	if err != nil {
+		fmt.Print("handler.checkout.Checkout() Idempotency Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+	if replay != nil {
+		if replay.ContentType != nil && *replay.ContentType != "" {
+			w.Header().Set("Content-Type", *replay.ContentType)
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(*replay.StatusCode)
+		if replay.ResponseBody != nil {
+			w.Write([]byte(*replay.ResponseBody))
+		}
 		return
 	}
 
+	capture := &responseCapture{ResponseWriter: w, statusCode: http.StatusOK}
+	h.createCheckout(capture, r, checkoutReq)
+
+	// The outcome must be recorded even if the client has already gone away.
+	ctx := context.WithoutCancel(r.Context())
+	if capture.statusCode >= http.StatusInternalServerError {
+		err = h.idempotency.Abort(ctx, scope, key)
+	} else {
+		err = h.idempotency.Complete(ctx, scope, key, capture.statusCode, capture.Header().Get("Content-Type"), capture.body.Bytes())
+	}
+	if err != nil {
+		fmt.Print("handler.checkout.Checkout() Idempotency Error: ", err.Error())
+	}
+}
+
+// createCheckout runs the checkout and writes its response.
+func (h *CheckoutHandler) createCheckout(w http.ResponseWriter, r *http.Request, checkoutReq transport.CheckoutRequest) {
 	res, err := h.service.CreateCheckout(r.Context(), checkoutReq)
 	if err != nil {
 		fmt.Print("handler.checkout.Checkout() Error: ", err.Error())
-		if err.Error() == "no products found for the given UUIDs" {
-			http.Error(w, "No Products Found", http.StatusBadRequest)
-			return
-		}
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(res)
+}
+
+// responseCapture passes a response through to the client while keeping a copy of
+// its status code and body.
+type responseCapture struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (c *responseCapture) WriteHeader(statusCode int) {
+	c.statusCode = statusCode
+	c.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (c *responseCapture) Write(b []byte) (int, error) {
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+func (h *CheckoutHandler) VoidTransaction(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	var refundReq transport.RefundRequest
+	err := json.NewDecoder(r.Body).Decode(&refundReq)
+	if err != nil {
+		fmt.Print("handler.checkout.VoidTransaction() Decode Error: ", err.Error())
+		writeError(w, errInvalidBody)
+		return
+	}
 
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	res, err := h.service.VoidTransaction(r.Context(), idStr, refundReq)
+	if err != nil {
+		fmt.Print("handler.checkout.VoidTransaction() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *CheckoutHandler) RefundTransaction(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	var refundReq transport.RefundRequest
+	err := json.NewDecoder(r.Body).Decode(&refundReq)
+	if err != nil {
+		fmt.Print("handler.checkout.RefundTransaction() Decode Error: ", err.Error())
+		writeError(w, errInvalidBody)
+		return
+	}
+
+	res, err := h.service.RefundTransaction(r.Context(), idStr, refundReq)
+	if err != nil {
+		fmt.Print("handler.checkout.RefundTransaction() Error: ", err.Error())
+		writeError(w, err)
 		return
 	}
 
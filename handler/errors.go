@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fendi/modul-03-task/barcode"
+	"fendi/modul-03-task/repository"
+	"fendi/modul-03-task/service"
+	"fendi/modul-03-task/transport"
+	"fmt"
+	"net/http"
+)
+
+// errInvalidBody is reported when a request body cannot be decoded.
+var errInvalidBody = errors.New("invalid request body")
+
+// errorMapping ties a domain error to the HTTP status and error code it is reported with.
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// errorMappings is the single place where domain errors become HTTP responses.
+// Errors are matched with errors.Is in order, so wrapped errors map like their cause.
+var errorMappings = []errorMapping{
+	{errInvalidBody, http.StatusBadRequest, "invalid_body"},
+	{service.ErrInvalidQuery, http.StatusBadRequest, "invalid_query"},
+	{service.ErrIdempotencyKeyInvalid, http.StatusBadRequest, "invalid_idempotency_key"},
+	{repository.ErrNoProductsFound, http.StatusBadRequest, "no_products_found"},
+	{repository.ErrAmountTooLarge, http.StatusBadRequest, "amount_too_large"},
+	{service.ErrStoreRequired, http.StatusBadRequest, "store_required"},
+
+	{service.ErrUnauthenticated, http.StatusUnauthorized, "unauthenticated"},
+	{service.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{service.ErrStoreForbidden, http.StatusForbidden, "store_forbidden"},
+
+	{errRouteNotFound, http.StatusNotFound, "not_found"},
+	{repository.ErrProductNotFound, http.StatusNotFound, "product_not_found"},
+	{repository.ErrCategoryNotFound, http.StatusNotFound, "category_not_found"},
+	{repository.ErrTransactionNotFound, http.StatusNotFound, "transaction_not_found"},
+	{service.ErrNoLabels, http.StatusNotFound, "no_labels"},
+	{repository.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+	{repository.ErrAPIKeyNotFound, http.StatusNotFound, "api_key_not_found"},
+	{repository.ErrStoreNotFound, http.StatusNotFound, "store_not_found"},
+
+	{errMethodNotAllowed, http.StatusMethodNotAllowed, "method_not_allowed"},
+
+	{repository.ErrNothingToRefund, http.StatusConflict, "nothing_to_refund"},
+	{repository.ErrDuplicateSKU, http.StatusConflict, "duplicate_sku"},
+	{repository.ErrDuplicateBarcode, http.StatusConflict, "duplicate_barcode"},
+	{repository.ErrStockNotTracked, http.StatusConflict, "stock_not_tracked"},
+	{repository.ErrNegativeStock, http.StatusConflict, "negative_stock"},
+	{service.ErrIdempotencyKeyInProgress, http.StatusConflict, "idempotency_key_in_progress"},
+	{service.ErrLastOwner, http.StatusConflict, "last_owner"},
+
+	{repository.ErrInvalidRequest, http.StatusUnprocessableEntity, "validation_failed"},
+	{repository.ErrInvalidRefund, http.StatusUnprocessableEntity, "invalid_refund"},
+	{repository.ErrInvalidStockAdjustment, http.StatusUnprocessableEntity, "invalid_stock_adjustment"},
+	{service.ErrStockNotEditable, http.StatusUnprocessableEntity, "stock_not_editable"},
+	{service.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, "idempotency_key_reused"},
+	{barcode.ErrInvalid, http.StatusUnprocessableEntity, "invalid_barcode"},
+
+	{service.ErrSKUGenerationFailed, http.StatusServiceUnavailable, "sku_generation_failed"},
+}
+
+// writeError writes err as a JSON error response. Unknown errors become a 500 without
+// exposing their message.
+func writeError(w http.ResponseWriter, err error) {
+	var stockErr *repository.StockError
+	if errors.As(err, &stockErr) {
+		details := make([]transport.ErrorDetailResponse, 0, len(stockErr.Issues))
+		for _, issue := range stockErr.Issues {
+			message := fmt.Sprintf("product %s: %s (requested %d", issue.ProductID, issue.Reason, issue.Requested)
+			if issue.Available != nil {
+				message += fmt.Sprintf(", available %d", *issue.Available)
+			}
+			details = append(details, transport.ErrorDetailResponse{
+				Field:   "items",
+				Message: message + ")",
+			})
+		}
+
+		writeErrorResponse(w, http.StatusConflict, transport.ErrorResponse{
+			Code:    "checkout_rejected",
+			Message: "one or more items cannot be fulfilled",
+			Details: details,
+		})
+		return
+	}
+
+	for _, m := range errorMappings {
+		if !errors.Is(err, m.err) {
+			continue
+		}
+
+		res := transport.ErrorResponse{Code: m.code, Message: m.err.Error()}
+		var validationErr *repository.ValidationError
+		if errors.As(err, &validationErr) {
+			for _, f := range validationErr.Fields {
+				res.Details = append(res.Details, transport.ErrorDetailResponse{Field: f.Field, Message: f.Message})
+			}
+		}
+
+		writeErrorResponse(w, m.status, res)
+		return
+	}
+
+	writeErrorResponse(w, http.StatusInternalServerError, transport.ErrorResponse{
+		Code:    "internal_error",
+		Message: "internal server error",
+	})
+}
+
+func writeErrorResponse(w http.ResponseWriter, status int, res transport.ErrorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(res)
+}
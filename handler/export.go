@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"fendi/modul-03-task/export"
+	"fendi/modul-03-task/transport"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// exportFormat returns the file format a request asks for: the format query
+// parameter, or else the first media type of the Accept header that is JSON or an
+// export format. It returns "" for JSON, the default.
+func exportFormat(r *http.Request) string {
+	if format := strings.ToLower(r.URL.Query().Get("format")); format != "" {
+		if format == "json" {
+			return ""
+		}
+		return format
+	}
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, _ := strings.Cut(accept, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if rejected(params) {
+			continue
+		}
+
+		if format := export.FormatOf(mediaType); format != "" {
+			return format
+		}
+		if mediaType == "application/json" || mediaType == "application/*" || mediaType == "*/*" {
+			return ""
+		}
+	}
+
+	return ""
+}
+
+// rejected reports whether the parameters of an Accept media type rule it out with
+// q=0.
+func rejected(params string) bool {
+	for _, param := range strings.Split(params, ";") {
+		q, ok := strings.CutPrefix(strings.TrimSpace(param), "q=")
+		if ok && strings.Trim(q, "0.") == "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeExport sends an export as a file download. Once the file has started
+// streaming an error can no longer become an error response, so the response is
+// aborted instead, which keeps clients from taking a cut short file as complete.
+func writeExport(w http.ResponseWriter, res transport.ExportResponse, caller string) {
+	w.Header().Set("Content-Type", res.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", res.Filename))
+	w.WriteHeader(http.StatusOK)
+
+	err := res.WriteTo(w)
+	if err != nil {
+		fmt.Print(caller+" Error: ", err.Error())
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fendi/modul-03-task/service"
 	"fendi/modul-03-task/transport"
+	"fendi/modul-03-task/validation"
 	"fmt"
 	"net/http"
+	"strconv"
 )
 
 type ProductHandler struct {
@@ -16,26 +18,26 @@ func NewProductHandler(service *service.ProductService) *ProductHandler {
 	return &ProductHandler{service: service}
 }
 
-func (h *ProductHandler) HandleProduct(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodGet {
-		h.GetAllProduct(w, r)
-		return
-	}
-	if r.Method == http.MethodPost {
-		h.CreateProduct(w, r)
-		return
-	}
-
-	http.NotFound(w, r)
-}
-
 func (h *ProductHandler) GetAllProduct(w http.ResponseWriter, r *http.Request) {
-	keyword := r.URL.Query().Get("search")
+	query := r.URL.Query()
+	req := transport.ProductListRequest{
+		Search:     query.Get("search"),
+		CategoryID: query.Get("category_id"),
+		SKU:        query.Get("sku"),
+		Barcode:    query.Get("barcode"),
+		MinPrice:   query.Get("min_price"),
+		MaxPrice:   query.Get("max_price"),
+		Stock:      query.Get("stock"),
+		Sort:       query.Get("sort"),
+		Page:       query.Get("page"),
+		Limit:      query.Get("limit"),
+		Cursor:     query.Get("cursor"),
+	}
 
-	res, err := h.service.GetAllProduct(r.Context(), keyword)
+	res, err := h.service.GetAllProduct(r.Context(), req)
 	if err != nil {
 		fmt.Print("handler.product.GetAllProduct() Error: ", err.Error())
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -49,14 +51,21 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&productReq)
 	if err != nil {
 		fmt.Print("handler.product.CreateProduct() Decode Error: ", err.Error())
-		http.Error(w, "Invalid Request Body", http.StatusBadRequest)
+		writeError(w, errInvalidBody)
+		return
+	}
+
+	err = validation.Validate(productReq)
+	if err != nil {
+		fmt.Print("handler.product.CreateProduct() Validation Error: ", err.Error())
+		writeError(w, err)
 		return
 	}
 
 	res, err := h.service.CreateProduct(r.Context(), productReq)
 	if err != nil {
 		fmt.Print("handler.product.CreateProduct() Error: ", err.Error())
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -65,40 +74,13 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(res)
 }
 
-func (h *ProductHandler) HandleProductItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodGet {
-		h.GetProductByUUID(w, r)
-		return
-	}
-	if r.Method == http.MethodPut {
-		h.UpdateProduct(w, r)
-		return
-	}
-	if r.Method == http.MethodDelete {
-		h.DeleteProduct(w, r)
-		return
-	}
-
-	http.NotFound(w, r)
-}
-
 func (h *ProductHandler) GetProductByUUID(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/products/"):]
-	if idStr == "" {
-		fmt.Print("handler.product.GetProductByUUID() Error: ID is empty")
-		http.Error(w, "Not Found", http.StatusNotFound)
-		return
-	}
+	idStr := r.PathValue("uuid")
 
 	res, err := h.service.GetProductByUUID(r.Context(), idStr)
 	if err != nil {
 		fmt.Print("handler.product.GetProductByUUID() Error: ", err.Error())
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-	if res.ID == "" {
-		fmt.Print("handler.product.GetProductByUUID() Error: Product not found")
-		http.Error(w, "Not Found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 
@@ -108,36 +90,27 @@ func (h *ProductHandler) GetProductByUUID(w http.ResponseWriter, r *http.Request
 }
 
 func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/products/"):]
-	if idStr == "" {
-		fmt.Print("handler.product.UpdateProduct() Error: ID is empty")
-		http.Error(w, "Not Found", http.StatusNotFound)
-		return
-	}
+	idStr := r.PathValue("uuid")
 
 	var productReq transport.ProductRequest
 	err := json.NewDecoder(r.Body).Decode(&productReq)
 	if err != nil {
 		fmt.Print("handler.product.UpdateProduct() Decode Error: ", err.Error())
-		http.Error(w, "Invalid Request Body", http.StatusBadRequest)
+		writeError(w, errInvalidBody)
 		return
 	}
 
-	res, err := h.service.UpdateProduct(r.Context(), idStr, productReq)
+	err = validation.Validate(productReq)
 	if err != nil {
-		if err.Error() == "product not found" {
-			fmt.Print("handler.product.UpdateProduct() Error: Product not found")
-			http.Error(w, "Bad Request: Product not found", http.StatusBadRequest)
-			return
-		}
-		if err.Error() == "category not found" {
-			fmt.Print("handler.product.UpdateProduct() Error: Category not found")
-			http.Error(w, "Bad Request: Category not found", http.StatusBadRequest)
-			return
-		}
+		fmt.Print("handler.product.UpdateProduct() Validation Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
 
+	res, err := h.service.UpdateProduct(r.Context(), idStr, productReq)
+	if err != nil {
 		fmt.Print("handler.product.UpdateProduct() Error: ", err.Error())
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -147,17 +120,12 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/products/"):]
-	if idStr == "" {
-		fmt.Print("handler.product.DeleteProduct() Error: ID is empty")
-		http.Error(w, "Not Found", http.StatusNotFound)
-		return
-	}
+	idStr := r.PathValue("uuid")
 
 	err := h.service.DeleteProduct(r.Context(), idStr)
 	if err != nil {
 		fmt.Print("handler.product.DeleteProduct() Error: ", err.Error())
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -168,3 +136,132 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 		Status: "OK",
 	})
 }
+
+func (h *ProductHandler) GetDeletedProducts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := transport.TrashListRequest{
+		Cursor: query.Get("cursor"),
+		Limit:  query.Get("limit"),
+	}
+
+	res, err := h.service.GetDeletedProducts(r.Context(), req)
+	if err != nil {
+		fmt.Print("handler.product.GetDeletedProducts() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *ProductHandler) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	res, err := h.service.RestoreProduct(r.Context(), idStr)
+	if err != nil {
+		fmt.Print("handler.product.RestoreProduct() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *ProductHandler) GetStockMovements(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	query := r.URL.Query()
+	req := transport.StockMovementListRequest{
+		Cursor: query.Get("cursor"),
+		Limit:  query.Get("limit"),
+	}
+
+	res, err := h.service.GetStockMovements(r.Context(), idStr, req)
+	if err != nil {
+		fmt.Print("handler.product.GetStockMovements() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *ProductHandler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	var adjustmentReq transport.StockAdjustmentRequest
+	err := json.NewDecoder(r.Body).Decode(&adjustmentReq)
+	if err != nil {
+		fmt.Print("handler.product.AdjustStock() Decode Error: ", err.Error())
+		writeError(w, errInvalidBody)
+		return
+	}
+
+	err = validation.Validate(adjustmentReq)
+	if err != nil {
+		fmt.Print("handler.product.AdjustStock() Validation Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	res, err := h.service.AdjustStock(r.Context(), idStr, adjustmentReq)
+	if err != nil {
+		fmt.Print("handler.product.AdjustStock() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *ProductHandler) GetProductLabel(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	req := transport.LabelRequest{Format: r.URL.Query().Get("format")}
+
+	res, err := h.service.GetProductLabel(r.Context(), idStr, req)
+	if err != nil {
+		fmt.Print("handler.product.GetProductLabel() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	writeLabel(w, res)
+}
+
+func (h *ProductHandler) GetCategoryLabels(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	query := r.URL.Query()
+	req := transport.LabelRequest{
+		Format: query.Get("format"),
+		Page:   query.Get("page"),
+	}
+
+	res, err := h.service.GetCategoryLabels(r.Context(), idStr, req)
+	if err != nil {
+		fmt.Print("handler.product.GetCategoryLabels() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	writeLabel(w, res)
+}
+
+// writeLabel writes a rendered label with its page position in the X-Page and
+// X-Total-Pages headers.
+func writeLabel(w http.ResponseWriter, res transport.LabelResponse) {
+	w.Header().Set("Content-Type", res.ContentType)
+	w.Header().Set("X-Page", strconv.Itoa(res.Page))
+	w.Header().Set("X-Total-Pages", strconv.Itoa(res.TotalPages))
+	w.WriteHeader(http.StatusOK)
+	w.Write(res.Body)
+}
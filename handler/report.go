@@ -3,6 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"fendi/modul-03-task/service"
+	"fendi/modul-03-task/transport"
 	"fmt"
 	"net/http"
 )
@@ -16,10 +17,16 @@ func NewReportHandler(service *service.ReportService) *ReportHandler {
 }
 
 func (h *ReportHandler) HandleTodayReport(w http.ResponseWriter, r *http.Request) {
-	res, err := h.service.GetTodayReport(r.Context())
+	query := r.URL.Query()
+	req := transport.ReportRequest{
+		TZ:  query.Get("tz"),
+		Top: query.Get("top"),
+	}
+
+	res, err := h.service.GetTodayReport(r.Context(), req)
 	if err != nil {
 		fmt.Printf("handler.report.HandleTodayReport() Error: %v", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -29,13 +36,92 @@ func (h *ReportHandler) HandleTodayReport(w http.ResponseWriter, r *http.Request
 }
 
 func (h *ReportHandler) HandleReportByDate(w http.ResponseWriter, r *http.Request) {
-	startDate := r.URL.Query().Get("start_date")
-	endDate := r.URL.Query().Get("end_date")
+	query := r.URL.Query()
+	req := transport.ReportRequest{
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+		TZ:        query.Get("tz"),
+		Top:       query.Get("top"),
+	}
+
+	w.Header().Add("Vary", "Accept")
+	if format := exportFormat(r); format != "" {
+		res, err := h.service.ExportReport(r.Context(), req, format)
+		if err != nil {
+			fmt.Printf("handler.report.HandleReportByDate() Error: %v", err)
+			writeError(w, err)
+			return
+		}
+
+		writeExport(w, res, "handler.report.HandleReportByDate()")
+		return
+	}
 
-	res, err := h.service.GetReportByDate(r.Context(), startDate, endDate)
+	res, err := h.service.GetReportByDate(r.Context(), req)
 	if err != nil {
 		fmt.Printf("handler.report.HandleReportByDate() Error: %v", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *ReportHandler) HandleSalesSeries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := transport.SalesSeriesRequest{
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+		TZ:        query.Get("tz"),
+		Interval:  query.Get("interval"),
+	}
+
+	res, err := h.service.GetSalesSeries(r.Context(), req)
+	if err != nil {
+		fmt.Printf("handler.report.HandleSalesSeries() Error: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *ReportHandler) HandleCategoryReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := transport.ReportRequest{
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+		TZ:        query.Get("tz"),
+	}
+
+	res, err := h.service.GetCategoryReport(r.Context(), req)
+	if err != nil {
+		fmt.Printf("handler.report.HandleCategoryReport() Error: %v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *ReportHandler) HandleStoreReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := transport.ReportRequest{
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+		TZ:        query.Get("tz"),
+	}
+
+	res, err := h.service.GetStoreReport(r.Context(), req)
+	if err != nil {
+		fmt.Printf("handler.report.HandleStoreReport() Error: %v", err)
+		writeError(w, err)
 		return
 	}
 
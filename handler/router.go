@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"errors"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/service"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// requestIDHeader carries the ID of a request, taken from the client when it sends a
+// usable one and generated otherwise. It is echoed on every response.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the longest client request ID that is kept.
+const maxRequestIDLength = 128
+
+var (
+	// errRouteNotFound is reported when no route matches the request path.
+	errRouteNotFound = errors.New("route not found")
+	// errMethodNotAllowed is reported when the path exists but not for the request method.
+	errMethodNotAllowed = errors.New("method not allowed")
+)
+
+// Public is the role of routes anyone may call without an API key.
+const Public = ""
+
+// Route is one entry of the route table. Role is the least privileged role allowed
+// to call it, or Public.
+type Route struct {
+	Method string
+	Path   string
+	Role   string
+}
+
+// Router dispatches requests by method and path using http.ServeMux patterns, so
+// handlers read path parameters with r.PathValue. On top of the mux it ignores a
+// trailing slash, answers OPTIONS, tags every request with an X-Request-ID, and
+// reports unknown paths and methods as JSON 404 and 405 responses with an Allow
+// header. Routes that are not Public are
+// guarded by auth.
+type Router struct {
+	mux *http.ServeMux
+	// paths holds the fallback of every path without a method, so a literal path such
+	// as /products/trash and a wildcard one such as GET /products/{uuid} do not conflict.
+	// mux hands it the requests none of its routes match.
+	paths   *http.ServeMux
+	auth    *AuthHandler
+	routes  []Route
+	methods map[string][]string
+}
+
+func NewRouter(auth *AuthHandler) *Router {
+	rt := &Router{
+		mux:     http.NewServeMux(),
+		paths:   http.NewServeMux(),
+		auth:    auth,
+		methods: make(map[string][]string),
+	}
+	rt.mux.Handle("/", rt.paths)
+	rt.paths.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, errRouteNotFound)
+	})
+
+	return rt
+}
+
+// Handle registers h for method and path, callable by role and the roles above it.
+// Path segments such as {uuid} are wildcards. A GET route also answers HEAD.
+func (rt *Router) Handle(method, path, role string, h http.HandlerFunc) {
+	pattern := path
+	if pattern == "/" {
+		// "/" alone would match every path.
+		pattern = "/{$}"
+	}
+
+	if _, ok := rt.methods[pattern]; !ok {
+		rt.paths.HandleFunc(pattern, rt.fallback(pattern))
+	}
+	if role != Public {
+		h = rt.auth.Require(role, h)
+	}
+	rt.methods[pattern] = append(rt.methods[pattern], method)
+	rt.mux.HandleFunc(method+" "+pattern, h)
+	rt.routes = append(rt.routes, Route{Method: method, Path: path, Role: role})
+}
+
+// Routes lists the registered routes in registration order.
+func (rt *Router) Routes() []Route {
+	return slices.Clone(rt.routes)
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if len(r.URL.Path) > 1 && strings.HasSuffix(r.URL.Path, "/") {
+		r.URL.Path = strings.TrimRight(r.URL.Path, "/")
+		r.URL.RawPath = strings.TrimRight(r.URL.RawPath, "/")
+		if r.URL.Path == "" {
+			r.URL.Path = "/"
+		}
+	}
+
+	id := requestID(r.Header.Get(requestIDHeader))
+	w.Header().Set(requestIDHeader, id)
+	r = r.WithContext(service.WithRequestID(r.Context(), id))
+
+	// A literal path such as /products/trash takes precedence over wildcards, also
+	// for methods it has no route for: PUT /products/trash is a 405, not a
+	// PUT /products/{uuid} with "trash" as the UUID.
+	if _, pattern := rt.paths.Handler(r); !strings.Contains(pattern, "{") && !rt.hasRoute(pattern, r.Method) {
+		rt.paths.ServeHTTP(w, r)
+		return
+	}
+
+	rt.mux.ServeHTTP(w, r)
+}
+
+// requestID returns the client request ID when it is printable ASCII of a sane
+// length, otherwise a new UUID.
+func requestID(id string) string {
+	if id == "" || len(id) > maxRequestIDLength {
+		return helper.GenerateUUID()
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return helper.GenerateUUID()
+		}
+	}
+
+	return id
+}
+
+// fallback handles requests whose path matches pattern but whose method has no route.
+func (rt *Router) fallback(pattern string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", rt.allow(pattern))
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		writeError(w, errMethodNotAllowed)
+	}
+}
+
+// hasRoute reports whether a route is registered for method and pattern. GET routes
+// also answer HEAD.
+func (rt *Router) hasRoute(pattern, method string) bool {
+	methods := rt.methods[pattern]
+	if method == http.MethodHead && slices.Contains(methods, http.MethodGet) {
+		return true
+	}
+
+	return slices.Contains(methods, method)
+}
+
+// allow builds the Allow header value for pattern.
+func (rt *Router) allow(pattern string) string {
+	methods := slices.Clone(rt.methods[pattern])
+	if i := slices.Index(methods, http.MethodGet); i >= 0 && !slices.Contains(methods, http.MethodHead) {
+		methods = slices.Insert(methods, i+1, http.MethodHead)
+	}
+	methods = append(methods, http.MethodOptions)
+
+	return strings.Join(methods, ", ")
+}
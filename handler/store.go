@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"fendi/modul-03-task/service"
+	"fendi/modul-03-task/transport"
+	"fendi/modul-03-task/validation"
+	"fmt"
+	"net/http"
+)
+
+type StoreHandler struct {
+	service *service.StoreService
+}
+
+func NewStoreHandler(service *service.StoreService) *StoreHandler {
+	return &StoreHandler{service: service}
+}
+
+func (h *StoreHandler) GetAllStore(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetAllStore(r.Context())
+	if err != nil {
+		fmt.Print("handler.store.GetAllStore() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *StoreHandler) CreateStore(w http.ResponseWriter, r *http.Request) {
+	var storeReq transport.StoreRequest
+	err := json.NewDecoder(r.Body).Decode(&storeReq)
+	if err != nil {
+		fmt.Print("handler.store.CreateStore() Decode Error: ", err.Error())
+		writeError(w, errInvalidBody)
+		return
+	}
+
+	err = validation.Validate(storeReq)
+	if err != nil {
+		fmt.Print("handler.store.CreateStore() Validation Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	res, err := h.service.CreateStore(r.Context(), storeReq)
+	if err != nil {
+		fmt.Print("handler.store.CreateStore() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *StoreHandler) GetStoreByUUID(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	res, err := h.service.GetStoreByUUID(r.Context(), idStr)
+	if err != nil {
+		fmt.Print("handler.store.GetStoreByUUID() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *StoreHandler) UpdateStore(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	var storeReq transport.StoreRequest
+	err := json.NewDecoder(r.Body).Decode(&storeReq)
+	if err != nil {
+		fmt.Print("handler.store.UpdateStore() Decode Error: ", err.Error())
+		writeError(w, errInvalidBody)
+		return
+	}
+
+	err = validation.Validate(storeReq)
+	if err != nil {
+		fmt.Print("handler.store.UpdateStore() Validation Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	res, err := h.service.UpdateStore(r.Context(), idStr, storeReq)
+	if err != nil {
+		fmt.Print("handler.store.UpdateStore() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
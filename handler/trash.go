@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"encoding/json"
+	"fendi/modul-03-task/service"
+	"fendi/modul-03-task/transport"
+	"fendi/modul-03-task/validation"
+	"fmt"
+	"net/http"
+)
+
+type TrashHandler struct {
+	service *service.TrashService
+}
+
+func NewTrashHandler(service *service.TrashService) *TrashHandler {
+	return &TrashHandler{service: service}
+}
+
+func (h *TrashHandler) PurgeTrash(w http.ResponseWriter, r *http.Request) {
+	var purgeReq transport.PurgeRequest
+	err := json.NewDecoder(r.Body).Decode(&purgeReq)
+	if err != nil {
+		fmt.Print("handler.trash.PurgeTrash() Decode Error: ", err.Error())
+		writeError(w, errInvalidBody)
+		return
+	}
+
+	err = validation.Validate(purgeReq)
+	if err != nil {
+		fmt.Print("handler.trash.PurgeTrash() Validation Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	res, err := h.service.PurgeTrash(r.Context(), purgeReq)
+	if err != nil {
+		fmt.Print("handler.trash.PurgeTrash() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"encoding/json"
+	"fendi/modul-03-task/service"
+	"fendi/modul-03-task/transport"
+	"fendi/modul-03-task/validation"
+	"fmt"
+	"net/http"
+)
+
+type UserHandler struct {
+	service *service.UserService
+}
+
+func NewUserHandler(service *service.UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+func (h *UserHandler) GetAllUser(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetAllUser(r.Context())
+	if err != nil {
+		fmt.Print("handler.user.GetAllUser() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var userReq transport.UserRequest
+	err := json.NewDecoder(r.Body).Decode(&userReq)
+	if err != nil {
+		fmt.Print("handler.user.CreateUser() Decode Error: ", err.Error())
+		writeError(w, errInvalidBody)
+		return
+	}
+
+	err = validation.Validate(userReq)
+	if err != nil {
+		fmt.Print("handler.user.CreateUser() Validation Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	res, err := h.service.CreateUser(r.Context(), userReq)
+	if err != nil {
+		fmt.Print("handler.user.CreateUser() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *UserHandler) GetUserByUUID(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	res, err := h.service.GetUserByUUID(r.Context(), idStr)
+	if err != nil {
+		fmt.Print("handler.user.GetUserByUUID() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	var userReq transport.UserRequest
+	err := json.NewDecoder(r.Body).Decode(&userReq)
+	if err != nil {
+		fmt.Print("handler.user.UpdateUser() Decode Error: ", err.Error())
+		writeError(w, errInvalidBody)
+		return
+	}
+
+	err = validation.Validate(userReq)
+	if err != nil {
+		fmt.Print("handler.user.UpdateUser() Validation Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	res, err := h.service.UpdateUser(r.Context(), idStr, userReq)
+	if err != nil {
+		fmt.Print("handler.user.UpdateUser() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	err := h.service.DeleteUser(r.Context(), idStr)
+	if err != nil {
+		fmt.Print("handler.user.DeleteUser() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(transport.StatusResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+	})
+}
+
+func (h *UserHandler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	res, err := h.service.GetAPIKeys(r.Context(), idStr)
+	if err != nil {
+		fmt.Print("handler.user.GetAPIKeys() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *UserHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	var keyReq transport.APIKeyRequest
+	err := json.NewDecoder(r.Body).Decode(&keyReq)
+	if err != nil {
+		fmt.Print("handler.user.CreateAPIKey() Decode Error: ", err.Error())
+		writeError(w, errInvalidBody)
+		return
+	}
+
+	err = validation.Validate(keyReq)
+	if err != nil {
+		fmt.Print("handler.user.CreateAPIKey() Validation Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	res, err := h.service.CreateAPIKey(r.Context(), idStr, keyReq)
+	if err != nil {
+		fmt.Print("handler.user.CreateAPIKey() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *UserHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("uuid")
+
+	err := h.service.RevokeAPIKey(r.Context(), idStr)
+	if err != nil {
+		fmt.Print("handler.user.RevokeAPIKey() Error: ", err.Error())
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(transport.StatusResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+	})
+}
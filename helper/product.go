@@ -1,23 +1,174 @@
 package helper
 
 import (
-	"math/rand"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
-func GenerateSKU() string {
-	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	const length = 12
+// DefaultSKUPattern is used when no SKU pattern is configured.
+const DefaultSKUPattern = "ITEM-{RAND:12}"
 
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+// skuCharset is the alphabet of random blocks and of the check digit.
+const skuCharset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
 
-	var sb strings.Builder
-	sb.WriteString("ITEM-")
+// skuFallbackCategory names the category of products without one.
+const skuFallbackCategory = "GENERAL"
+
+var skuTokenPattern = regexp.MustCompile(`\{([A-Z]+)(?::([^}]*))?\}`)
+
+// SKUGenerator renders SKUs from a pattern of literal text and tokens:
+//
+//	{CAT} or {CAT:n}     first n letters and digits of the category name (default 3)
+//	{DATE} or {DATE:fmt} creation date, fmt built from YYYY, YY, MM and DD (default YYYYMMDD)
+//	{SEQ} or {SEQ:n}     sequence number, zero-padded to n digits (default 4)
+//	{RAND} or {RAND:n}   n random letters and digits (default 8)
+//
+// A pattern must contain {SEQ} or {RAND} so that retries can produce a new SKU. With
+// the check digit enabled a Luhn mod 36 character is appended.
+type SKUGenerator struct {
+	pattern    string
+	checkDigit bool
+}
+
+// SKUInput holds the product details a SKU is rendered from.
+type SKUInput struct {
+	Category string
+	Date     time.Time
+	Sequence int64
+}
+
+// NewSKUGenerator validates pattern and creates a generator. An empty pattern selects
+// DefaultSKUPattern.
+func NewSKUGenerator(pattern string, checkDigit bool) (*SKUGenerator, error) {
+	if pattern == "" {
+		pattern = DefaultSKUPattern
+	}
+
+	unique := false
+	for _, m := range skuTokenPattern.FindAllStringSubmatch(pattern, -1) {
+		switch m[1] {
+		case "CAT", "SEQ", "RAND":
+			if m[2] != "" {
+				n, err := strconv.Atoi(m[2])
+				if err != nil || n < 1 || n > 32 {
+					return nil, fmt.Errorf("invalid SKU pattern %q: %s width must be between 1 and 32", pattern, m[1])
+				}
+			}
+			if m[1] != "CAT" {
+				unique = true
+			}
+		case "DATE":
+		default:
+			return nil, fmt.Errorf("invalid SKU pattern %q: unknown token {%s}", pattern, m[1])
+		}
+	}
+	if !unique {
+		return nil, fmt.Errorf("invalid SKU pattern %q: must contain {SEQ} or {RAND}", pattern)
+	}
+
+	return &SKUGenerator{pattern: pattern, checkDigit: checkDigit}, nil
+}
+
+// UsesSequence reports whether the pattern contains {SEQ}.
+func (g *SKUGenerator) UsesSequence() bool {
+	return strings.Contains(g.pattern, "{SEQ")
+}
+
+// SequencePrefix renders the part of the pattern before {SEQ}. Products whose SKU
+// starts with it share one sequence, so a pattern like "{CAT}-{SEQ}" numbers each
+// category separately.
+func (g *SKUGenerator) SequencePrefix(in SKUInput) string {
+	before, _, _ := strings.Cut(g.pattern, "{SEQ")
+	return g.render(before, in)
+}
 
-	for range length {
-		sb.WriteByte(charset[rng.Intn(len(charset))])
+// Generate renders a SKU. Random blocks differ on every call.
+func (g *SKUGenerator) Generate(in SKUInput) string {
+	sku := g.render(g.pattern, in)
+	if g.checkDigit {
+		sku += string(luhnMod36(sku))
+	}
+
+	return sku
+}
+
+func (g *SKUGenerator) render(pattern string, in SKUInput) string {
+	return skuTokenPattern.ReplaceAllStringFunc(pattern, func(token string) string {
+		m := skuTokenPattern.FindStringSubmatch(token)
+		switch m[1] {
+		case "CAT":
+			return skuCategoryCode(in.Category, tokenWidth(m[2], 3))
+		case "DATE":
+			layout := m[2]
+			if layout == "" {
+				layout = "YYYYMMDD"
+			}
+			return in.Date.Format(strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02").Replace(layout))
+		case "SEQ":
+			return fmt.Sprintf("%0*d", tokenWidth(m[2], 4), in.Sequence)
+		case "RAND":
+			n := tokenWidth(m[2], 8)
+			var sb strings.Builder
+			for range n {
+				sb.WriteByte(skuCharset[rand.IntN(len(skuCharset))])
+			}
+			return sb.String()
+		default:
+			return token
+		}
+	})
+}
+
+func tokenWidth(param string, def int) int {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return def
+	}
+
+	return n
+}
+
+// skuCategoryCode returns the first n letters and digits of name in upper case.
+func skuCategoryCode(name string, n int) string {
+	var sb strings.Builder
+	for _, r := range strings.ToUpper(name) {
+		if strings.ContainsRune(skuCharset, r) {
+			sb.WriteRune(r)
+		}
+		if sb.Len() == n {
+			return sb.String()
+		}
+	}
+	if sb.Len() == 0 {
+		return skuCategoryCode(skuFallbackCategory, n)
 	}
 
 	return sb.String()
 }
+
+// luhnMod36 computes the Luhn mod N check character of s over skuCharset. Characters
+// outside the charset, such as separators, are skipped.
+func luhnMod36(s string) byte {
+	const n = len(skuCharset)
+
+	sum := 0
+	double := true
+	for i := len(s) - 1; i >= 0; i-- {
+		v := strings.IndexByte(skuCharset, s[i])
+		if v < 0 {
+			continue
+		}
+		if double {
+			v *= 2
+			v = v/n + v%n
+		}
+		sum += v
+		double = !double
+	}
+
+	return skuCharset[(n-sum%n)%n]
+}
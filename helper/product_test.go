@@ -0,0 +1,133 @@
+package helper_test
+
+import (
+	"fendi/modul-03-task/helper"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestNewSKUGenerator(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{pattern: ""},
+		{pattern: "{CAT}-{SEQ}"},
+		{pattern: "{DATE:YYMM}-{RAND:32}"},
+		{pattern: "ITEM-{CAT}", wantErr: true},
+		{pattern: "{DATE}", wantErr: true},
+		{pattern: "{FOO}-{SEQ}", wantErr: true},
+		{pattern: "{SEQ:0}", wantErr: true},
+		{pattern: "{RAND:33}", wantErr: true},
+		{pattern: "{CAT:x}-{SEQ}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		_, err := helper.NewSKUGenerator(tt.pattern, false)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewSKUGenerator(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSKUGeneratorGenerate(t *testing.T) {
+	date := time.Date(2026, time.October, 18, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		pattern    string
+		checkDigit bool
+		in         helper.SKUInput
+		want       string
+	}{
+		// Category codes keep letters and digits only.
+		{pattern: "{CAT}-{SEQ}", in: helper.SKUInput{Category: "Mie Instan", Sequence: 7}, want: "MIE-0007"},
+		{pattern: "{CAT:5}-{SEQ}", in: helper.SKUInput{Category: "Mie Instan", Sequence: 7}, want: "MIEIN-0007"},
+		{pattern: "{CAT}-{SEQ}", in: helper.SKUInput{Category: "7-Eleven", Sequence: 7}, want: "7EL-0007"},
+		{pattern: "{CAT:8}-{SEQ}", in: helper.SKUInput{Category: "Teh", Sequence: 7}, want: "TEH-0007"},
+		{pattern: "{CAT}-{SEQ}", in: helper.SKUInput{Category: "", Sequence: 7}, want: "GEN-0007"},
+		{pattern: "{CAT}-{SEQ}", in: helper.SKUInput{Category: "!!!", Sequence: 7}, want: "GEN-0007"},
+
+		{pattern: "{DATE}-{SEQ}", in: helper.SKUInput{Date: date, Sequence: 1}, want: "20261018-0001"},
+		{pattern: "{DATE:YYMM}/{SEQ:3}", in: helper.SKUInput{Date: date, Sequence: 1}, want: "2610/001"},
+		{pattern: "{DATE:DD.MM.YYYY}-{SEQ}", in: helper.SKUInput{Date: date, Sequence: 1}, want: "18.10.2026-0001"},
+
+		// Sequences are zero-padded, never truncated.
+		{pattern: "{SEQ}", in: helper.SKUInput{Sequence: 0}, want: "0000"},
+		{pattern: "{SEQ:6}", in: helper.SKUInput{Sequence: 42}, want: "000042"},
+		{pattern: "{SEQ:2}", in: helper.SKUInput{Sequence: 12345}, want: "12345"},
+
+		// Luhn mod 36 check characters, worked out by hand; separators are skipped.
+		{pattern: "ITEM-{SEQ}", checkDigit: true, in: helper.SKUInput{Sequence: 1}, want: "ITEM-00016"},
+		{pattern: "ITEM-{SEQ}", checkDigit: true, in: helper.SKUInput{Sequence: 0}, want: "ITEM-00008"},
+		{pattern: "A{SEQ:1}", checkDigit: true, in: helper.SKUInput{Sequence: 0}, want: "A0Q"},
+		{pattern: "Z{SEQ:1}", checkDigit: true, in: helper.SKUInput{Sequence: 0}, want: "Z01"},
+		{pattern: "{SEQ}", checkDigit: true, in: helper.SKUInput{Sequence: 0}, want: "00000"},
+	}
+
+	for _, tt := range tests {
+		g, err := helper.NewSKUGenerator(tt.pattern, tt.checkDigit)
+		if err != nil {
+			t.Fatalf("NewSKUGenerator(%q) error = %v", tt.pattern, err)
+		}
+		if got := g.Generate(tt.in); got != tt.want {
+			t.Errorf("Generate(%q, %+v) = %q, want %q", tt.pattern, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSKUGeneratorRandomBlocks(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		checkDigit bool
+		want       *regexp.Regexp
+	}{
+		{pattern: "", want: regexp.MustCompile(`^ITEM-[0-9A-Z]{12}$`)},
+		{pattern: "{RAND}", want: regexp.MustCompile(`^[0-9A-Z]{8}$`)},
+		{pattern: "P-{RAND:3}", checkDigit: true, want: regexp.MustCompile(`^P-[0-9A-Z]{4}$`)},
+	}
+
+	for _, tt := range tests {
+		g, err := helper.NewSKUGenerator(tt.pattern, tt.checkDigit)
+		if err != nil {
+			t.Fatalf("NewSKUGenerator(%q) error = %v", tt.pattern, err)
+		}
+		for range 20 {
+			if got := g.Generate(helper.SKUInput{}); !tt.want.MatchString(got) {
+				t.Fatalf("Generate(%q) = %q, want a match of %s", tt.pattern, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestSKUGeneratorSequencePrefix(t *testing.T) {
+	date := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		pattern    string
+		in         helper.SKUInput
+		wantUses   bool
+		wantPrefix string
+	}{
+		{pattern: "{CAT}-{SEQ}", in: helper.SKUInput{Category: "Mie Instan"}, wantUses: true, wantPrefix: "MIE-"},
+		{pattern: "{DATE:YYMM}-{SEQ:5}-X", in: helper.SKUInput{Date: date}, wantUses: true, wantPrefix: "2610-"},
+		{pattern: "{SEQ}", wantUses: true, wantPrefix: ""},
+		{pattern: "ITEM-{RAND}", wantUses: false},
+	}
+
+	for _, tt := range tests {
+		g, err := helper.NewSKUGenerator(tt.pattern, false)
+		if err != nil {
+			t.Fatalf("NewSKUGenerator(%q) error = %v", tt.pattern, err)
+		}
+		if got := g.UsesSequence(); got != tt.wantUses {
+			t.Errorf("UsesSequence(%q) = %v, want %v", tt.pattern, got, tt.wantUses)
+		}
+		if !tt.wantUses {
+			continue
+		}
+		if got := g.SequencePrefix(tt.in); got != tt.wantPrefix {
+			t.Errorf("SequencePrefix(%q) = %q, want %q", tt.pattern, got, tt.wantPrefix)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Package label renders printable product labels with the name, price and barcode of
+// a product, as SVG or PNG, one per page or as a sheet of many.
+package label
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"fendi/modul-03-task/barcode"
+	"fendi/modul-03-task/model"
+)
+
+// Output formats.
+const (
+	FormatSVG = "svg"
+	FormatPNG = "png"
+)
+
+// A label is 50 x 25 mm, laid out on a canvas of Width x Height pixels (16 px per mm).
+const (
+	Width  = 800
+	Height = 400
+)
+
+// A sheet holds SheetColumns x SheetRows labels separated by sheetGap pixels.
+const (
+	SheetColumns = 3
+	SheetRows    = 10
+	SheetSize    = SheetColumns * SheetRows
+
+	sheetGap = 24
+)
+
+const (
+	margin     = 24
+	nameScale  = 5
+	priceScale = 6
+	textScale  = 4
+	barsTop    = 150
+	barsBottom = 320
+)
+
+// Label holds what is printed on one label. Price is omitted when nil.
+type Label struct {
+	Name    string
+	Price   *model.Money
+	Barcode barcode.Barcode
+}
+
+// ContentType returns the media type of format.
+func ContentType(format string) string {
+	if format == FormatPNG {
+		return "image/png"
+	}
+
+	return "image/svg+xml"
+}
+
+// ValidFormat reports whether format can be rendered.
+func ValidFormat(format string) bool {
+	return format == FormatSVG || format == FormatPNG
+}
+
+// Render draws a single label.
+func Render(l Label, format string) ([]byte, error) {
+	c, err := newCanvas(format, Width, Height)
+	if err != nil {
+		return nil, err
+	}
+	drawLabel(c, 0, 0, l)
+
+	return c.bytes()
+}
+
+// RenderSheet draws up to SheetSize labels on one sheet, row by row, with a thin border
+// around each label to cut along.
+func RenderSheet(labels []Label, format string) ([]byte, error) {
+	if len(labels) > SheetSize {
+		return nil, fmt.Errorf("a sheet holds at most %d labels", SheetSize)
+	}
+
+	width := SheetColumns*Width + (SheetColumns+1)*sheetGap
+	height := SheetRows*Height + (SheetRows+1)*sheetGap
+	c, err := newCanvas(format, width, height)
+	if err != nil {
+		return nil, err
+	}
+	for i, l := range labels {
+		x := sheetGap + (i%SheetColumns)*(Width+sheetGap)
+		y := sheetGap + (i/SheetColumns)*(Height+sheetGap)
+		c.border(x, y, Width, Height)
+		drawLabel(c, x, y, l)
+	}
+
+	return c.bytes()
+}
+
+// drawLabel lays out l with its top left corner at x, y.
+func drawLabel(c canvas, x, y int, l Label) {
+	c.text(x+margin, y+margin, nameScale, false, fit(l.Name, Width-2*margin, nameScale))
+	if l.Price != nil {
+		c.text(x+margin, y+margin+10*nameScale, priceScale, false, l.Price.Rupiah())
+	}
+
+	modules := l.Barcode.Modules
+	total := len(modules) + 2*barcode.QuietZone
+	module := max((Width-2*margin)/total, 1)
+	left := x + (Width-module*len(modules))/2
+	for i := 0; i < len(modules); {
+		if !modules[i] {
+			i++
+			continue
+		}
+		j := i
+		for j < len(modules) && modules[j] {
+			j++
+		}
+		c.rect(left+i*module, y+barsTop, (j-i)*module, barsBottom-barsTop)
+		i = j
+	}
+
+	c.text(x+Width/2, y+barsBottom+textScale*4, textScale, true, fit(l.Barcode.Text, Width-2*margin, textScale))
+}
+
+// fit truncates s with an ellipsis so that it is at most width pixels wide at scale.
+func fit(s string, width, scale int) string {
+	n := (width + scale) / ((glyphWidth + 1) * scale)
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+
+	return string(r[:n-3]) + "..."
+}
+
+// canvas is a drawing surface in pixels with black ink on white.
+type canvas interface {
+	rect(x, y, w, h int)
+	border(x, y, w, h int)
+	// text draws s with its top at y, starting at x or centred on x.
+	text(x, y, scale int, centre bool, s string)
+	bytes() ([]byte, error)
+}
+
+func newCanvas(format string, width, height int) (canvas, error) {
+	switch format {
+	case FormatSVG:
+		c := &svgCanvas{}
+		fmt.Fprintf(&c.buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%smm" height="%smm" viewBox="0 0 %d %d">`,
+			mm(width), mm(height), width, height)
+		fmt.Fprintf(&c.buf, `<rect width="%d" height="%d" fill="#fff"/>`, width, height)
+		return c, nil
+	case FormatPNG:
+		img := image.NewGray(image.Rect(0, 0, width, height))
+		draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+		return &pngCanvas{img: img}, nil
+	default:
+		return nil, fmt.Errorf("unsupported label format %q", format)
+	}
+}
+
+// mm converts pixels to millimetres.
+func mm(px int) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.4f", float64(px)/16), "0"), ".")
+}
+
+type svgCanvas struct {
+	buf bytes.Buffer
+}
+
+func (c *svgCanvas) rect(x, y, w, h int) {
+	fmt.Fprintf(&c.buf, `<rect x="%d" y="%d" width="%d" height="%d"/>`, x, y, w, h)
+}
+
+func (c *svgCanvas) border(x, y, w, h int) {
+	fmt.Fprintf(&c.buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="#ccc" stroke-width="2"/>`, x, y, w, h)
+}
+
+func (c *svgCanvas) text(x, y, scale int, centre bool, s string) {
+	anchor := "start"
+	if centre {
+		anchor = "middle"
+	}
+	// A monospace font at 10 px per font pixel has about the cap height of the bitmap font.
+	fmt.Fprintf(&c.buf, `<text x="%d" y="%d" font-family="monospace" font-size="%d" text-anchor="%s">`,
+		x, y+glyphHeight*scale, 10*scale, anchor)
+	c.buf.WriteString(escapeXML(s))
+	c.buf.WriteString(`</text>`)
+}
+
+func (c *svgCanvas) bytes() ([]byte, error) {
+	c.buf.WriteString(`</svg>`)
+	return c.buf.Bytes(), nil
+}
+
+func escapeXML(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;").Replace(s)
+}
+
+type pngCanvas struct {
+	img *image.Gray
+}
+
+func (c *pngCanvas) rect(x, y, w, h int) {
+	draw.Draw(c.img, image.Rect(x, y, x+w, y+h), image.Black, image.Point{}, draw.Src)
+}
+
+func (c *pngCanvas) border(x, y, w, h int) {
+	grey := image.NewUniform(color.Gray{Y: 0xcc})
+	for _, r := range []image.Rectangle{
+		image.Rect(x, y, x+w, y+2),
+		image.Rect(x, y+h-2, x+w, y+h),
+		image.Rect(x, y, x+2, y+h),
+		image.Rect(x+w-2, y, x+w, y+h),
+	} {
+		draw.Draw(c.img, r, grey, image.Point{}, draw.Src)
+	}
+}
+
+func (c *pngCanvas) text(x, y, scale int, centre bool, s string) {
+	runes := []rune(s)
+	advance := (glyphWidth + 1) * scale
+	if centre {
+		x -= (len(runes)*advance - scale) / 2
+	}
+	for i, r := range runes {
+		g := glyph(r)
+		for row := range glyphHeight {
+			for col := range glyphWidth {
+				if g[row]&(1<<(glyphWidth-1-col)) != 0 {
+					c.rect(x+i*advance+col*scale, y+row*scale, scale, scale)
+				}
+			}
+		}
+	}
+}
+
+func (c *pngCanvas) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, c.img)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
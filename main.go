@@ -5,13 +5,19 @@ import (
 	"fendi/modul-03-task/config"
 	"fendi/modul-03-task/database"
 	"fendi/modul-03-task/handler"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
 	"fendi/modul-03-task/repository"
+	"fendi/modul-03-task/repository/memory"
 	"fendi/modul-03-task/service"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/spf13/viper"
 )
@@ -36,67 +42,259 @@ func main() {
 	conf := config.Config{
 		AppPort: viper.GetString("APP_PORT"),
 		DBConn:  viper.GetString("DB_CONN"),
+
+		CheckoutStrictStock: viper.GetBool("CHECKOUT_STRICT_STOCK"),
+
+		SKUPattern:    viper.GetString("SKU_PATTERN"),
+		SKUCheckDigit: viper.GetBool("SKU_CHECK_DIGIT"),
+
+		StoreTimezone: viper.GetString("STORE_TIMEZONE"),
+
+		OwnerAPIKey: viper.GetString("OWNER_API_KEY"),
 	}
 
-	db, err := database.InitDB(conf.DBConn)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(conf, os.Args[2:])
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "routes" {
+		runRoutes()
+		return
+	}
+
+	skuGenerator, err := helper.NewSKUGenerator(conf.SKUPattern, conf.SKUCheckDigit)
 	if err != nil {
-		log.Fatalf("Error: Unable to connect to database, %v", err.Error())
+		log.Fatalf("Error: %v", err.Error())
+	}
+
+	// Without a configured store time zone reports follow the server's. Every store
+	// of the deployment shares it.
+	location := time.Local
+	if conf.StoreTimezone != "" {
+		location, err = time.LoadLocation(conf.StoreTimezone)
+		if err != nil {
+			log.Fatalf("Error: invalid STORE_TIMEZONE %q: %v", conf.StoreTimezone, err.Error())
+		}
+	}
+
+	if conf.OwnerAPIKey != "" && len(conf.OwnerAPIKey) < service.MinOwnerKeyLength {
+		log.Fatalf("Error: OWNER_API_KEY must be at least %d characters long", service.MinOwnerKeyLength)
+	}
+
+	var categoryRepo repository.CategoryRepository
+	var productRepo repository.ProductRepository
+	var checkoutRepo repository.CheckoutRepository
+	var reportRepo repository.ReportRepository
+	var stockRepo repository.StockRepository
+	var idempotencyRepo repository.IdempotencyRepository
+	var userRepo repository.UserRepository
+	var storeRepo repository.StoreRepository
+	var auditRepo repository.AuditRepository
+
+	if strings.HasPrefix(conf.DBConn, "memory://") {
+		store := memory.NewStore()
+		categoryRepo = memory.NewCategoryRepository(store)
+		productRepo = memory.NewProductRepository(store)
+		checkoutRepo = memory.NewCheckoutRepository(store)
+		reportRepo = memory.NewReportRepository(store)
+		stockRepo = memory.NewStockRepository(store)
+		idempotencyRepo = memory.NewIdempotencyRepository(store)
+		userRepo = memory.NewUserRepository(store)
+		storeRepo = memory.NewStoreRepository(store)
+		auditRepo = memory.NewAuditRepository(store)
+
+		log.Println("Using in-memory storage, data will be lost on restart.")
+	} else {
+		db, dialect, err := database.InitDB(conf.DBConn)
+		if err != nil {
+			log.Fatalf("Error: Unable to connect to database, %v", err.Error())
+		}
+		defer db.Close()
+
+		categoryRepo = repository.NewCategoryRepository(db, dialect)
+		productRepo = repository.NewProductRepository(db, dialect)
+		checkoutRepo = repository.NewCheckoutRepository(db, dialect)
+		reportRepo = repository.NewReportRepository(db, dialect)
+		stockRepo = repository.NewStockRepository(db, dialect)
+		idempotencyRepo = repository.NewIdempotencyRepository(db, dialect)
+		userRepo = repository.NewUserRepository(db, dialect)
+		storeRepo = repository.NewStoreRepository(db, dialect)
+		auditRepo = repository.NewAuditRepository(db, dialect)
+	}
+
+	if conf.OwnerAPIKey == "" {
+		log.Println("OWNER_API_KEY is not set, only API keys of stored users are accepted.")
 	}
-	defer db.Close()
 
-	categoryRepo := repository.NewCategoryRepository(db)
 	categoryService := service.NewCategoryService(categoryRepo)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 
-	productRepo := repository.NewProductRepository(db)
-	productService := service.NewProductService(productRepo, categoryRepo)
+	productService := service.NewProductService(productRepo, categoryRepo, stockRepo, skuGenerator)
 	productHandler := handler.NewProductHandler(productService)
 
-	checkoutRepo := repository.NewCheckoutRepository(db)
-	checkoutService := service.NewCheckoutService(checkoutRepo, productRepo)
-	checkoutHandler := handler.NewCheckoutHandler(checkoutService)
+	checkoutService := service.NewCheckoutService(checkoutRepo, productRepo, conf.CheckoutStrictStock, location)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo)
+	checkoutHandler := handler.NewCheckoutHandler(checkoutService, idempotencyService)
 
-	reportRepo := repository.NewReportRepository(db)
-	reportService := service.NewReportService(reportRepo)
+	reportService := service.NewReportService(reportRepo, location)
 	reportHandler := handler.NewReportHandler(reportService)
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == "GET" {
-			if r.URL.Path == "/" {
-				w.Header().Set("Content-Type", "application/json")
+	authService := service.NewAuthService(userRepo, storeRepo, conf.OwnerAPIKey)
+	authHandler := handler.NewAuthHandler(authService)
+
+	userService := service.NewUserService(userRepo, storeRepo)
+	userHandler := handler.NewUserHandler(userService)
+
+	storeService := service.NewStoreService(storeRepo)
+	storeHandler := handler.NewStoreHandler(storeService)
+
+	auditService := service.NewAuditService(auditRepo, location)
+	auditHandler := handler.NewAuditHandler(auditService)
+
+	trashService := service.NewTrashService(productRepo, categoryRepo, location)
+	trashHandler := handler.NewTrashHandler(trashService)
+
+	router := newRouter(authHandler, userHandler, storeHandler, productHandler, categoryHandler, checkoutHandler, reportHandler, auditHandler, trashHandler)
+
+	fmt.Println("Server is up and running")
+	fmt.Printf("http://localhost:%s\n", conf.AppPort)
+
+	addr := fmt.Sprintf(":%s", conf.AppPort)
+	err = http.ListenAndServe(addr, router)
+	if err != nil {
+		fmt.Printf("Error: Unable to start server, %v", err.Error())
+		return
+	}
+}
+
+// runMigrate handles the "migrate up|down [steps]|status" command.
+func runMigrate(conf config.Config, args []string) {
+	if len(args) == 0 {
+		log.Fatal("Usage: migrate up | down [steps] | status")
+	}
 
-				json.NewEncoder(w).Encode(StatusResponse{
-					Code:   200,
-					Status: "OK",
-				})
-				return
+	db, dialect, err := database.InitDB(conf.DBConn)
+	if err != nil {
+		log.Fatalf("Error: Unable to connect to database, %v", err.Error())
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(db, dialect)
+		if err != nil {
+			log.Fatalf("Error: Migration failed, %v", err.Error())
+		}
+		fmt.Printf("Applied %d migration(s)\n", len(applied))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatalf("Error: Invalid number of steps %q", args[1])
 			}
 		}
 
-		http.Error(w, "Not Found", http.StatusNotFound)
+		reverted, err := database.MigrateDown(db, dialect, steps)
+		if err != nil {
+			log.Fatalf("Error: Migration failed, %v", err.Error())
+		}
+		fmt.Printf("Reverted %d migration(s)\n", len(reverted))
+	case "status":
+		statuses, err := database.GetMigrationStatus(db, dialect)
+		if err != nil {
+			log.Fatalf("Error: Unable to read migration status, %v", err.Error())
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied at " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%06d_%s\t%s\n", status.Version, status.Name, state)
+		}
+	default:
+		log.Fatalf("Error: Unknown migrate command %q", args[0])
+	}
+}
+
+// newRouter registers every API route with the least privileged role allowed to
+// call it.
+func newRouter(authHandler *handler.AuthHandler, userHandler *handler.UserHandler, storeHandler *handler.StoreHandler, productHandler *handler.ProductHandler, categoryHandler *handler.CategoryHandler, checkoutHandler *handler.CheckoutHandler, reportHandler *handler.ReportHandler, auditHandler *handler.AuditHandler, trashHandler *handler.TrashHandler) *handler.Router {
+	router := handler.NewRouter(authHandler)
+
+	router.Handle(http.MethodGet, "/", handler.Public, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		json.NewEncoder(w).Encode(StatusResponse{
+			Code:   200,
+			Status: "OK",
+		})
 	})
 
-	http.HandleFunc("/products", productHandler.HandleProduct)
+	router.Handle(http.MethodGet, "/me", model.RoleCashier, authHandler.GetMe)
 
-	http.HandleFunc("/products/", productHandler.HandleProductItem)
+	router.Handle(http.MethodGet, "/users", model.RoleOwner, userHandler.GetAllUser)
+	router.Handle(http.MethodPost, "/users", model.RoleOwner, userHandler.CreateUser)
+	router.Handle(http.MethodGet, "/users/{uuid}", model.RoleOwner, userHandler.GetUserByUUID)
+	router.Handle(http.MethodPut, "/users/{uuid}", model.RoleOwner, userHandler.UpdateUser)
+	router.Handle(http.MethodDelete, "/users/{uuid}", model.RoleOwner, userHandler.DeleteUser)
+	router.Handle(http.MethodGet, "/users/{uuid}/api-keys", model.RoleOwner, userHandler.GetAPIKeys)
+	router.Handle(http.MethodPost, "/users/{uuid}/api-keys", model.RoleOwner, userHandler.CreateAPIKey)
+	router.Handle(http.MethodDelete, "/api-keys/{uuid}", model.RoleOwner, userHandler.RevokeAPIKey)
 
-	http.HandleFunc("/categories", categoryHandler.HandleCategory)
+	router.Handle(http.MethodGet, "/stores", model.RoleOwner, storeHandler.GetAllStore)
+	router.Handle(http.MethodPost, "/stores", model.RoleOwner, storeHandler.CreateStore)
+	router.Handle(http.MethodGet, "/stores/{uuid}", model.RoleOwner, storeHandler.GetStoreByUUID)
+	router.Handle(http.MethodPut, "/stores/{uuid}", model.RoleOwner, storeHandler.UpdateStore)
 
-	http.HandleFunc("/categories/", categoryHandler.HandleCategoryItem)
+	router.Handle(http.MethodGet, "/products", model.RoleCashier, productHandler.GetAllProduct)
+	router.Handle(http.MethodPost, "/products", model.RoleManager, productHandler.CreateProduct)
+	router.Handle(http.MethodGet, "/products/{uuid}", model.RoleCashier, productHandler.GetProductByUUID)
+	router.Handle(http.MethodPut, "/products/{uuid}", model.RoleManager, productHandler.UpdateProduct)
+	router.Handle(http.MethodDelete, "/products/{uuid}", model.RoleManager, productHandler.DeleteProduct)
+	router.Handle(http.MethodGet, "/products/trash", model.RoleManager, productHandler.GetDeletedProducts)
+	router.Handle(http.MethodPost, "/products/{uuid}/restore", model.RoleManager, productHandler.RestoreProduct)
+	router.Handle(http.MethodGet, "/products/{uuid}/stock-movements", model.RoleManager, productHandler.GetStockMovements)
+	router.Handle(http.MethodPost, "/products/{uuid}/stock-adjustments", model.RoleManager, productHandler.AdjustStock)
+	router.Handle(http.MethodGet, "/products/{uuid}/label", model.RoleCashier, productHandler.GetProductLabel)
 
-	http.HandleFunc("/checkouts", checkoutHandler.HandleCheckout)
+	router.Handle(http.MethodGet, "/categories", model.RoleCashier, categoryHandler.GetAllCategory)
+	router.Handle(http.MethodPost, "/categories", model.RoleManager, categoryHandler.CreateCategory)
+	router.Handle(http.MethodGet, "/categories/{uuid}", model.RoleCashier, categoryHandler.GetCategoryByUUID)
+	router.Handle(http.MethodPut, "/categories/{uuid}", model.RoleManager, categoryHandler.UpdateCategory)
+	router.Handle(http.MethodDelete, "/categories/{uuid}", model.RoleManager, categoryHandler.DeleteCategory)
+	router.Handle(http.MethodGet, "/categories/trash", model.RoleManager, categoryHandler.GetDeletedCategories)
+	router.Handle(http.MethodPost, "/categories/{uuid}/restore", model.RoleManager, categoryHandler.RestoreCategory)
+	router.Handle(http.MethodGet, "/categories/{uuid}/labels", model.RoleCashier, productHandler.GetCategoryLabels)
 
-	http.HandleFunc("/reports", reportHandler.HandleReportByDate)
+	router.Handle(http.MethodGet, "/checkouts", model.RoleManager, checkoutHandler.GetAllTransaction)
+	router.Handle(http.MethodPost, "/checkouts", model.RoleCashier, checkoutHandler.Checkout)
+	router.Handle(http.MethodGet, "/checkouts/{uuid}", model.RoleCashier, checkoutHandler.GetTransactionByUUID)
+	router.Handle(http.MethodPost, "/checkouts/{uuid}/void", model.RoleManager, checkoutHandler.VoidTransaction)
+	router.Handle(http.MethodPost, "/checkouts/{uuid}/refunds", model.RoleManager, checkoutHandler.RefundTransaction)
 
-	http.HandleFunc("/reports/hari-ini", reportHandler.HandleTodayReport)
+	router.Handle(http.MethodGet, "/reports", model.RoleManager, reportHandler.HandleReportByDate)
+	router.Handle(http.MethodGet, "/reports/hari-ini", model.RoleManager, reportHandler.HandleTodayReport)
+	router.Handle(http.MethodGet, "/reports/timeseries", model.RoleManager, reportHandler.HandleSalesSeries)
+	router.Handle(http.MethodGet, "/reports/categories", model.RoleManager, reportHandler.HandleCategoryReport)
+	router.Handle(http.MethodGet, "/reports/stores", model.RoleOwner, reportHandler.HandleStoreReport)
 
-	fmt.Println("Server is up and running")
-	fmt.Printf("http://localhost:%s\n", conf.AppPort)
+	router.Handle(http.MethodGet, "/audit", model.RoleOwner, auditHandler.GetAuditEntries)
+	router.Handle(http.MethodPost, "/trash/purge", model.RoleOwner, trashHandler.PurgeTrash)
 
-	addr := fmt.Sprintf(":%s", conf.AppPort)
-	err = http.ListenAndServe(addr, nil)
-	if err != nil {
-		fmt.Printf("Error: Unable to start server, %v", err.Error())
-		return
+	return router
+}
+
+// runRoutes handles the "routes" command, printing the route table. Handlers are
+// never called here, so they are built without services or a database.
+func runRoutes() {
+	router := newRouter(handler.NewAuthHandler(nil), handler.NewUserHandler(nil), handler.NewStoreHandler(nil), handler.NewProductHandler(nil), handler.NewCategoryHandler(nil), handler.NewCheckoutHandler(nil, nil), handler.NewReportHandler(nil), handler.NewAuditHandler(nil), handler.NewTrashHandler(nil))
+	for _, route := range router.Routes() {
+		role := route.Role
+		if role == handler.Public {
+			role = "public"
+		}
+		fmt.Printf("%-7s %-36s %s\n", route.Method, route.Path, role)
 	}
 }
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions.
+const (
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge"
+)
+
+// Audited entity types.
+const (
+	AuditEntityProduct  = "product"
+	AuditEntityCategory = "category"
+)
+
+// AuditChange holds a field's JSON value before and after a change, null when the
+// field had or has no value.
+type AuditChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// AuditEntry records one change to a product or category: who made it, in which
+// request and what it changed, keyed by field name. Actors are identified by their
+// user UUID, name and role at the time of the change. The database ID only orders
+// entries and backs the listing cursor; it is not exposed.
+type AuditEntry struct {
+	ID         int64                  `json:"-"`
+	UUID       string                 `json:"id"`
+	ActorID    string                 `json:"actor_id"`
+	ActorName  string                 `json:"actor_name"`
+	ActorRole  string                 `json:"actor_role"`
+	Action     string                 `json:"action"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   string                 `json:"entity_id"`
+	Changes    map[string]AuditChange `json:"changes"`
+	RequestID  string                 `json:"request_id"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
@@ -1,9 +1,12 @@
 package model
 
-// Category represents a category entity.
+import "time"
+
+// Category represents a category entity. DeletedAt is only set on deleted categories.
 type Category struct {
-	ID          int64   `json:"id"`
-	UUID        string  `json:"uuid"`
-	Name        string  `json:"name"`
-	Description *string `json:"description"`
+	ID          int64      `json:"id"`
+	UUID        string     `json:"uuid"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
 }
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// IdempotencyRecord represents a stored Idempotency-Key together with the request
+// fingerprint and, once the request has finished, the response to replay.
+type IdempotencyRecord struct {
+	Scope        string     `json:"scope"`
+	Key          string     `json:"key"`
+	Fingerprint  string     `json:"fingerprint"`
+	StatusCode   *int       `json:"status_code"`
+	ContentType  *string    `json:"content_type"`
+	ResponseBody *string    `json:"response_body"`
+	CreatedAt    time.Time  `json:"created_at"`
+	CompletedAt  *time.Time `json:"completed_at"`
+}
+
+// IsCompleted reports whether the original request has finished and its response was stored.
+func (r IdempotencyRecord) IsCompleted() bool {
+	return r.StatusCode != nil
+}
@@ -0,0 +1,235 @@
+package model
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MoneyScale is the number of minor units (sen) in one Rupiah. It matches the two
+// decimal places of the DECIMAL(10, 2) money columns.
+const MoneyScale = 100
+
+// MaxMoney is the largest amount the DECIMAL(10, 2) money columns can store,
+// Rp 99.999.999,99.
+const MaxMoney Money = 99_999_999_99
+
+// Money is an amount of Rupiah held exactly as an integer number of sen, so sums
+// and products never pick up floating-point drift.
+//
+// Rounding rules:
+//   - Parsing a decimal with more than two fraction digits rounds to the nearest
+//     sen, halves away from zero (12.345 becomes 12.35, -12.345 becomes -12.35).
+//   - Floats read back from the database are rounded the same way.
+//   - Multiplying by a quantity and adding are exact, or fail with ErrMoneyOverflow
+//     when the result does not fit. Division, used for averages, rounds to the
+//     nearest sen, halves away from zero.
+//
+// In JSON a Money is a plain number with at most two decimals, for example 2500 or
+// 2500.5.
+type Money int64
+
+// ErrInvalidMoney is returned when a value cannot be read as an amount of money.
+var ErrInvalidMoney = errors.New("invalid money amount")
+
+// ErrMoneyOverflow is returned when arithmetic on money leaves the range of Money.
+var ErrMoneyOverflow = errors.New("money amount out of range")
+
+// decimalPattern matches plain decimal numbers with an optional exponent. big.Rat
+// alone would also take fractions such as "1/3" and hex or binary literals.
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// ParseMoney reads a decimal string such as "2500", "2500.50" or "2.5e3".
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return 0, ErrInvalidMoney
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, ErrInvalidMoney
+	}
+
+	return moneyFromRat(r)
+}
+
+// MoneyFromFloat converts f, in Rupiah, rounding to the nearest sen. f is read as
+// its shortest decimal representation, so 12.345 rounds up like the string "12.345"
+// even though the nearest float64 is slightly below it.
+func MoneyFromFloat(f float64) (Money, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, ErrInvalidMoney
+	}
+
+	return ParseMoney(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+func moneyFromRat(r *big.Rat) (Money, error) {
+	r = new(big.Rat).Mul(r, big.NewRat(MoneyScale, 1))
+
+	// Round half away from zero: truncate |r| + 1/2 and restore the sign.
+	abs := new(big.Rat).Abs(r)
+	abs.Add(abs, big.NewRat(1, 2))
+	n := new(big.Int).Quo(abs.Num(), abs.Denom())
+	if r.Sign() < 0 {
+		n.Neg(n)
+	}
+	if !n.IsInt64() {
+		return 0, ErrInvalidMoney
+	}
+
+	return Money(n.Int64()), nil
+}
+
+// Mul returns the amount for qty units priced at m.
+func (m Money) Mul(qty int64) (Money, error) {
+	n := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(qty))
+	if !n.IsInt64() {
+		return 0, ErrMoneyOverflow
+	}
+
+	return Money(n.Int64()), nil
+}
+
+// Add returns the sum of m and n.
+func (m Money) Add(n Money) (Money, error) {
+	sum := m + n
+	if (n > 0 && sum < m) || (n < 0 && sum > m) {
+		return 0, ErrMoneyOverflow
+	}
+
+	return sum, nil
+}
+
+// Div returns m split into n equal parts, rounded to the nearest sen with halves
+// away from zero. Dividing by zero returns zero.
+func (m Money) Div(n int64) Money {
+	if n == 0 {
+		return 0
+	}
+
+	negative := (m < 0) != (n < 0)
+	q, rem := int64(m)/n, int64(m)%n
+	if rem < 0 {
+		rem = -rem
+	}
+	if n < 0 {
+		n = -n
+	}
+	if rem*2 >= n {
+		if negative {
+			q--
+		} else {
+			q++
+		}
+	}
+
+	return Money(q)
+}
+
+// Float64 returns m in Rupiah. It is meant for comparisons and display, not arithmetic.
+func (m Money) Float64() float64 {
+	return float64(m) / MoneyScale
+}
+
+// String formats m in Rupiah without trailing zeros, for example "2500" or "2500.5".
+func (m Money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+	}
+
+	whole, frac := v/MoneyScale, v%MoneyScale
+	if whole < 0 {
+		whole = -whole
+	}
+	if frac < 0 {
+		frac = -frac
+	}
+	if frac == 0 {
+		return sign + strconv.FormatInt(whole, 10)
+	}
+
+	return strings.TrimRight(fmt.Sprintf("%s%d.%02d", sign, whole, frac), "0")
+}
+
+// Rupiah formats m the way prices are written in Indonesia, e.g. "Rp 12.500" or
+// "Rp 12.500,50".
+func (m Money) Rupiah() string {
+	v := int64(m)
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+
+	whole := strconv.FormatInt(v/MoneyScale, 10)
+	var sb strings.Builder
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteRune(d)
+	}
+	if frac := v % MoneyScale; frac != 0 {
+		fmt.Fprintf(&sb, ",%02d", frac)
+	}
+
+	return "Rp " + sign + sb.String()
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	// Only JSON numbers are accepted; a quoted amount is a client mistake.
+	if strings.HasPrefix(s, `"`) {
+		return fmt.Errorf("%w: expected a number, got %s", ErrInvalidMoney, s)
+	}
+
+	v, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+
+	return nil
+}
+
+// Scan reads a money column. Postgres returns DECIMAL values as text, SQLite as
+// integers or floats depending on how the value was stored.
+func (m *Money) Scan(src interface{}) error {
+	var err error
+	switch v := src.(type) {
+	case int64:
+		*m = Money(v) * MoneyScale
+	case float64:
+		*m, err = MoneyFromFloat(v)
+	case []byte:
+		*m, err = ParseMoney(string(v))
+	case string:
+		*m, err = ParseMoney(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidMoney, src)
+	}
+
+	return err
+}
+
+// Value writes m as a float64 in Rupiah. SQLite keeps DECIMAL columns as REAL
+// anyway, and the drivers format floats with the shortest exact representation,
+// so Postgres receives the same two-decimal value m holds.
+func (m Money) Value() (driver.Value, error) {
+	return m.Float64(), nil
+}
@@ -0,0 +1,177 @@
+package model_test
+
+import (
+	"errors"
+	"fendi/modul-03-task/model"
+	"math"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    model.Money
+		wantErr error
+	}{
+		{in: "2500", want: 250000},
+		{in: "2500.50", want: 250050},
+		{in: "2.5e3", want: 250000},
+		{in: " 12 ", want: 1200},
+		{in: "+5", want: 500},
+		{in: ".5", want: 50},
+		{in: "-0", want: 0},
+
+		// Halves round away from zero, everything else to the nearest sen.
+		{in: "12.345", want: 1235},
+		{in: "-12.345", want: -1235},
+		{in: "12.344", want: 1234},
+		{in: "0.005", want: 1},
+		{in: "-0.005", want: -1},
+		{in: "0.004", want: 0},
+
+		{in: "99999999.99", want: model.MaxMoney},
+		{in: "99999999.995", want: model.MaxMoney + 1},
+		{in: "92233720368547758.07", want: math.MaxInt64},
+		{in: "92233720368547758.08", wantErr: model.ErrInvalidMoney},
+		{in: "-92233720368547758.09", wantErr: model.ErrInvalidMoney},
+		{in: "1e400", wantErr: model.ErrInvalidMoney},
+
+		{in: "", wantErr: model.ErrInvalidMoney},
+		{in: "abc", wantErr: model.ErrInvalidMoney},
+		{in: `"2500"`, wantErr: model.ErrInvalidMoney},
+		{in: "1.2.3", wantErr: model.ErrInvalidMoney},
+		{in: "1e", wantErr: model.ErrInvalidMoney},
+		{in: "1/3", wantErr: model.ErrInvalidMoney},
+		{in: "0x10", wantErr: model.ErrInvalidMoney},
+		{in: "1_000", wantErr: model.ErrInvalidMoney},
+		{in: "Inf", wantErr: model.ErrInvalidMoney},
+		{in: "NaN", wantErr: model.ErrInvalidMoney},
+	}
+
+	for _, tt := range tests {
+		got, err := model.ParseMoney(tt.in)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseMoney(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyDiv(t *testing.T) {
+	tests := []struct {
+		m    model.Money
+		n    int64
+		want model.Money
+	}{
+		{m: 1000, n: 4, want: 250},
+		{m: 1000, n: 3, want: 333},
+		{m: 200, n: 3, want: 67},
+		{m: 5, n: 2, want: 3},
+		{m: -5, n: 2, want: -3},
+		{m: 5, n: -2, want: -3},
+		{m: -5, n: -2, want: 3},
+		{m: -4, n: 3, want: -1},
+		{m: 0, n: 7, want: 0},
+		{m: 700, n: 0, want: 0},
+		{m: model.MaxMoney, n: 1, want: model.MaxMoney},
+	}
+
+	for _, tt := range tests {
+		if got := tt.m.Div(tt.n); got != tt.want {
+			t.Errorf("Money(%d).Div(%d) = %d, want %d", tt.m, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMul(t *testing.T) {
+	tests := []struct {
+		m       model.Money
+		qty     int64
+		want    model.Money
+		wantErr error
+	}{
+		{m: 250000, qty: 3, want: 750000},
+		{m: 250000, qty: -3, want: -750000},
+		{m: model.MaxMoney, qty: 2, want: 2 * model.MaxMoney},
+		{m: math.MaxInt64 / 2, qty: 2, want: math.MaxInt64 - 1},
+		{m: math.MaxInt64, qty: 2, wantErr: model.ErrMoneyOverflow},
+		{m: math.MinInt64, qty: -1, wantErr: model.ErrMoneyOverflow},
+		{m: model.MaxMoney, qty: math.MaxInt64, wantErr: model.ErrMoneyOverflow},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.m.Mul(tt.qty)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Money(%d).Mul(%d) error = %v, want %v", tt.m, tt.qty, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("Money(%d).Mul(%d) = %d, want %d", tt.m, tt.qty, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyAdd(t *testing.T) {
+	tests := []struct {
+		m, n    model.Money
+		want    model.Money
+		wantErr error
+	}{
+		{m: 100, n: 250, want: 350},
+		{m: 100, n: -250, want: -150},
+		{m: math.MaxInt64, n: -1, want: math.MaxInt64 - 1},
+		{m: math.MinInt64, n: 1, want: math.MinInt64 + 1},
+		{m: math.MaxInt64, n: 1, wantErr: model.ErrMoneyOverflow},
+		{m: math.MinInt64, n: -1, wantErr: model.ErrMoneyOverflow},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.m.Add(tt.n)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Money(%d).Add(%d) error = %v, want %v", tt.m, tt.n, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("Money(%d).Add(%d) = %d, want %d", tt.m, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    model.Money
+		wantErr error
+	}{
+		{name: "sqlite integer", src: int64(2500), want: 250000},
+		{name: "sqlite real", src: 2500.5, want: 250050},
+		{name: "sqlite real rounded", src: 12.345, want: 1235},
+		{name: "sqlite negative real", src: -12.345, want: -1235},
+		{name: "postgres decimal", src: []byte("2500.50"), want: 250050},
+		{name: "postgres max decimal", src: []byte("99999999.99"), want: model.MaxMoney},
+		{name: "text", src: "-0.50", want: -50},
+		{name: "malformed bytes", src: []byte("abc"), wantErr: model.ErrInvalidMoney},
+		{name: "malformed text", src: "", wantErr: model.ErrInvalidMoney},
+		{name: "nan", src: math.NaN(), wantErr: model.ErrInvalidMoney},
+		{name: "infinity", src: math.Inf(1), wantErr: model.ErrInvalidMoney},
+		{name: "null", src: nil, wantErr: model.ErrInvalidMoney},
+		{name: "unsupported type", src: true, wantErr: model.ErrInvalidMoney},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got model.Money
+			err := got.Scan(tt.src)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Scan(%v) error = %v, want %v", tt.src, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("Scan(%v) = %d, want %d", tt.src, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,16 @@
 package model
 
-// Product represents a product entity.
+import "time"
+
+// Product represents a product entity. DeletedAt is only set on deleted products.
 type Product struct {
-	ID       int64     `json:"id"`
-	UUID     string    `json:"uuid"`
-	SKU      string    `json:"sku"`
-	Name     string    `json:"name"`
-	Stock    *int64    `json:"stock"`
-	Price    *float64  `json:"price"`
-	Category *Category `json:"category"`
+	ID        int64      `json:"id"`
+	UUID      string     `json:"uuid"`
+	SKU       string     `json:"sku"`
+	Barcode   *string    `json:"barcode"`
+	Name      string     `json:"name"`
+	Stock     *int64     `json:"stock"`
+	Price     *Money     `json:"price"`
+	Category  *Category  `json:"category"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Refund types. A void reverses everything still refundable on a transaction,
+// a refund reverses selected items.
+const (
+	RefundTypeVoid   = "void"
+	RefundTypeRefund = "refund"
+)
+
+// Refund represents a reversal record linked to a transaction. Like transactions,
+// refunds and their products are identified by UUID in responses.
+type Refund struct {
+	ID            int64          `json:"-"`
+	UUID          string         `json:"id"`
+	TransactionID int64          `json:"-"`
+	Type          string         `json:"type"`
+	Reason        string         `json:"reason"`
+	TotalAmount   Money          `json:"total_amount"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Details       []RefundDetail `json:"details"`
+}
+
+// RefundDetail represents the refunded quantity of one transaction detail line.
+type RefundDetail struct {
+	ID                  int64  `json:"-"`
+	RefundID            int64  `json:"-"`
+	TransactionDetailID int64  `json:"-"`
+	ProductID           int64  `json:"-"`
+	ProductUUID         string `json:"product_id"`
+	ProductName         string `json:"product_name"`
+	Price               Money  `json:"price"`
+	Quantity            int64  `json:"quantity"`
+	SubTotal            Money  `json:"sub_total"`
+}
@@ -1,13 +1,54 @@
 package model
 
+import "time"
+
+// ReportData holds the sales figures of a period. Refunds and voids made in the period
+// are netted out of TotalRevenue, ItemsSold and the product figures; GrossSales is the
+// total of the period's transactions before refunds.
 type ReportData struct {
-	TotalTransaction  int64             `json:"total_transaksi"`
-	TotalRevenue      float64           `json:"total_revenue"`
-	MostPurchasedItem MostPurchasedItem `json:"produk_terlaris"`
+	TotalTransaction int64          `json:"total_transaksi"`
+	TotalRevenue     Money          `json:"total_revenue"`
+	GrossSales       Money          `json:"gross_sales"`
+	ItemsSold        int64          `json:"items_sold"`
+	TopByQuantity    []ProductSales `json:"top_by_quantity"`
+	TopByRevenue     []ProductSales `json:"top_by_revenue"`
 }
 
-type MostPurchasedItem struct {
+// ProductSales is the quantity sold of one product and the revenue it brought in.
+type ProductSales struct {
 	ProductID   string `json:"id"`
 	ProductName string `json:"nama"`
 	Quantity    int64  `json:"qty_terjual"`
+	Revenue     Money  `json:"revenue"`
+}
+
+// SalesEntry is one sale or refund as it counts towards a sales time series: a
+// transaction counts once with its total and units, a refund or void subtracts its
+// total and units when it was made.
+type SalesEntry struct {
+	At           time.Time
+	Revenue      Money
+	Transactions int64
+	ItemsSold    int64
+}
+
+// CategorySales is the sales of the products of one category. CategoryID is nil for
+// products without a category; Deleted marks a soft-deleted category.
+type CategorySales struct {
+	CategoryID   *string
+	CategoryName string
+	Deleted      bool
+	Quantity     int64
+	Revenue      Money
+	Transactions int64
+}
+
+// StoreSales is the sales of one store. Refunds and voids made in the period count
+// in Refunded and are netted out of ItemsSold.
+type StoreSales struct {
+	Store        Store
+	GrossSales   Money
+	Refunded     Money
+	Transactions int64
+	ItemsSold    int64
 }
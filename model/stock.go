@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Stock movement types.
+const (
+	StockMovementSale       = "sale"
+	StockMovementAdjustment = "adjustment"
+	StockMovementRefund     = "refund"
+	StockMovementReceiving  = "receiving"
+)
+
+// MaxStock is the largest stock a product can hold, the limit of the INTEGER stock
+// column.
+const MaxStock int64 = 2147483647
+
+// StockMovement represents one append-only entry of the inventory ledger. Responses
+// identify the product by UUID; database IDs are not exposed.
+type StockMovement struct {
+	ID          int64     `json:"-"`
+	ProductID   int64     `json:"-"`
+	ProductUUID string    `json:"product_uuid"`
+	Type        string    `json:"type"`
+	Delta       int64     `json:"delta"`
+	Balance     int64     `json:"balance"`
+	Reference   string    `json:"reference"`
+	Note        *string   `json:"note"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// DefaultStoreUUID is the UUID of the store everything recorded before stores
+// existed was moved to.
+const DefaultStoreUUID = "b3f1c2a4-6d8e-4f3a-9c5b-1e2d3f4a5b6c"
+
+// Store represents one shop of the deployment. Categories, products and
+// transactions belong to a store; its name and address head printed documents.
+type Store struct {
+	ID        int64     `json:"id"`
+	UUID      string    `json:"uuid"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+}
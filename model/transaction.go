@@ -2,23 +2,45 @@ package model
 
 import "time"
 
-// Transaction represents a checkout transaction entity.
+// Transaction represents a checkout transaction entity. Like every other response,
+// it identifies transactions and products by UUID; database IDs are not exposed.
 type Transaction struct {
-	ID          int64               `json:"id"`
-	UUID        string              `json:"uuid"`
-	TotalAmount float64             `json:"total_amount"`
+	ID          int64               `json:"-"`
+	UUID        string              `json:"id"`
+	TotalAmount Money               `json:"total_amount"`
 	PurchasedAt time.Time           `json:"purchased_at"`
 	Details     []TransactionDetail `json:"details"`
+	Refunds     []Refund            `json:"refunds"`
 }
 
 // TransactionDetail represents the details of a transaction.
 type TransactionDetail struct {
-	ID            int64   `json:"id"`
-	TransactionID int64   `json:"transaction_id"`
-	ProductID     int64   `json:"product_id"`
-	ProductUUID   string  `json:"product_uuid"`
-	ProductName   string  `json:"product_name"`
-	Price         float64 `json:"price"`
-	Quantity      int64   `json:"quantity"`
-	SubTotal      float64 `json:"sub_total"`
+	ID            int64  `json:"-"`
+	TransactionID int64  `json:"-"`
+	ProductID     int64  `json:"-"`
+	ProductUUID   string `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	Price         Money  `json:"price"`
+	Quantity      int64  `json:"quantity"`
+	SubTotal      Money  `json:"sub_total"`
+	// RefundedQuantity is how many units of this line have been voided or refunded so far.
+	RefundedQuantity int64 `json:"refunded_quantity"`
+}
+
+// Reasons a checkout item could not be fulfilled as requested.
+const (
+	CheckoutIssueNotFound          = "not_found"
+	CheckoutIssueOutOfStock        = "out_of_stock"
+	CheckoutIssueInsufficientStock = "insufficient_stock"
+	CheckoutIssueInvalidQuantity   = "invalid_quantity"
+)
+
+// CheckoutIssue describes why a checkout item could not be fulfilled in full.
+// ProductID names the product the way the item referenced it: its UUID, or
+// "sku X" or "barcode X".
+type CheckoutIssue struct {
+	ProductID string `json:"product_id"`
+	Reason    string `json:"reason"`
+	Requested int64  `json:"requested"`
+	Available *int64 `json:"available,omitempty"`
 }
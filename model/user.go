@@ -0,0 +1,50 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Roles, from the most to the least privileged. A role may do everything the roles
+// after it may do.
+const (
+	RoleOwner   = "owner"
+	RoleManager = "manager"
+	RoleCashier = "cashier"
+)
+
+// Roles lists the roles from the most to the least privileged.
+var Roles = []string{RoleOwner, RoleManager, RoleCashier}
+
+// RoleAllows reports whether role may do what required may do.
+func RoleAllows(role, required string) bool {
+	have := slices.Index(Roles, role)
+	need := slices.Index(Roles, required)
+
+	return have >= 0 && need >= 0 && have <= need
+}
+
+// User represents a person or device that calls the API with its API keys. Store is
+// the store a manager or cashier works in, nil for owners, who use every store.
+type User struct {
+	ID        int64     `json:"id"`
+	UUID      string    `json:"uuid"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	Store     *Store    `json:"store"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// APIKey represents an API key of a user. Only the SHA-256 hash of the key is
+// stored; Prefix is the start of the key, to tell keys apart.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UUID       string     `json:"uuid"`
+	UserID     int64      `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fendi/modul-03-task/database"
+	"fendi/modul-03-task/model"
+	"fmt"
+	"time"
+)
+
+type auditRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+func NewAuditRepository(db *sql.DB, dialect database.Dialect) AuditRepository {
+	return &auditRepository{db: db, dialect: dialect}
+}
+
+func (r *auditRepository) GetAuditEntries(ctx context.Context, storeID int64, filter AuditFilter) ([]model.AuditEntry, error) {
+	query :=
+		`SELECT
+			id, uuid, actor_id, actor_name, actor_role, action, entity_type, entity_id, changes, request_id, created_at
+		FROM audit_entries
+		WHERE store_id = $1`
+
+	args := []interface{}{storeID}
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.EntityType != "" {
+		query += " AND entity_type = " + addArg(filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query += " AND entity_id = " + addArg(filter.EntityID)
+	}
+	if filter.Action != "" {
+		query += " AND action = " + addArg(filter.Action)
+	}
+	if filter.From != nil {
+		query += " AND created_at >= " + addArg(filter.From.UTC())
+	}
+	if filter.To != nil {
+		query += " AND created_at < " + addArg(filter.To.UTC())
+	}
+	if filter.BeforeID > 0 {
+		query += " AND id < " + addArg(filter.BeforeID)
+	}
+
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT " + addArg(filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		fmt.Println("repository.audit.GetAuditEntries() Query Error: ", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]model.AuditEntry, 0)
+	for rows.Next() {
+		var e model.AuditEntry
+		var changes string
+		err := rows.Scan(&e.ID, &e.UUID, &e.ActorID, &e.ActorName, &e.ActorRole, &e.Action, &e.EntityType, &e.EntityID, &changes, &e.RequestID, &e.CreatedAt)
+		if err != nil {
+			fmt.Println("repository.audit.GetAuditEntries() Scan Error: ", err.Error())
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(changes), &e.Changes)
+		if err != nil {
+			fmt.Println("repository.audit.GetAuditEntries() Unmarshal Error: ", err.Error())
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		fmt.Println("repository.audit.GetAuditEntries() Rows Error: ", err.Error())
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// recordAuditEntry appends e to the audit trail of the store inside tx, so the entry
+// is only kept when the change it describes is. ID and CreatedAt are filled in.
+func recordAuditEntry(ctx context.Context, tx *sql.Tx, storeID int64, e *model.AuditEntry) error {
+	e.CreatedAt = time.Now().UTC()
+
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		fmt.Println("repository.audit.recordAuditEntry() Marshal Error: ", err.Error())
+		return err
+	}
+
+	query := "INSERT INTO audit_entries (uuid, store_id, actor_id, actor_name, actor_role, action, entity_type, entity_id, changes, request_id, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id"
+	err = tx.QueryRowContext(ctx, query, e.UUID, storeID, e.ActorID, e.ActorName, e.ActorRole, e.Action, e.EntityType, e.EntityID, string(changes), e.RequestID, e.CreatedAt).Scan(&e.ID)
+	if err != nil {
+		fmt.Println("repository.audit.recordAuditEntry() Insert Error: ", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -3,33 +3,51 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fendi/modul-03-task/database"
 	"fendi/modul-03-task/helper"
 	"fendi/modul-03-task/model"
 	"fmt"
+	"time"
 )
 
-type CategoryRepository struct {
-	db *sql.DB
+type categoryRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
 }
 
-func NewCategoryRepository(db *sql.DB) *CategoryRepository {
-	return &CategoryRepository{db: db}
+func NewCategoryRepository(db *sql.DB, dialect database.Dialect) CategoryRepository {
+	return &categoryRepository{db: db, dialect: dialect}
 }
 
-func (r *CategoryRepository) GetAllCategory(ctx context.Context, keyword string) ([]model.Category, error) {
+func (r *categoryRepository) GetAllCategory(ctx context.Context, storeID int64, filter CategoryFilter) ([]model.Category, error) {
 	query :=
 		`SELECT 
 			id, uuid, name, description 
 		FROM categories 
 		WHERE deleted_at IS NULL`
 
-	var args []interface{}
-	if len(keyword) > 0 {
-		query += " AND name ILIKE '%' || $1 || '%'"
-		args = append(args, keyword)
+	where, args := r.filterConditions(storeID, filter)
+	query += where
+
+	sortExpr := ""
+	if filter.Sort == SortByName {
+		sortExpr = "name"
+	}
+	if filter.After != nil {
+		var cond string
+		cond, args = keysetCondition(sortExpr, "id", filter.Desc, *filter.After, args)
+		query += " AND " + cond
 	}
 
-	query += ` ORDER BY id ASC`
+	query += orderBy(sortExpr, "id", filter.Desc)
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 && filter.After == nil {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -55,7 +73,34 @@ func (r *CategoryRepository) GetAllCategory(ctx context.Context, keyword string)
 	return categories, nil
 }
 
-func (r *CategoryRepository) GetCategoryByUUID(ctx context.Context, uuid string) (*model.Category, error) {
+func (r *categoryRepository) CountCategory(ctx context.Context, storeID int64, filter CategoryFilter) (int64, error) {
+	where, args := r.filterConditions(storeID, filter)
+
+	var total int64
+	query := "SELECT COUNT(*) FROM categories WHERE deleted_at IS NULL" + where
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&total)
+	if err != nil {
+		fmt.Println("repository.category.CountCategory() Query Error: ", err.Error())
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// filterConditions builds the AND conditions shared by GetAllCategory and CountCategory.
+func (r *categoryRepository) filterConditions(storeID int64, filter CategoryFilter) (string, []interface{}) {
+	where := " AND store_id = $1"
+	args := []interface{}{storeID}
+
+	if len(filter.Keyword) > 0 {
+		args = append(args, filter.Keyword)
+		where += " AND " + r.dialect.ILike("name", fmt.Sprintf("$%d", len(args)))
+	}
+
+	return where, args
+}
+
+func (r *categoryRepository) GetCategoryByUUID(ctx context.Context, storeID int64, uuid string) (*model.Category, error) {
 	isValidUUID := helper.IsValidUUID(uuid)
 	if !isValidUUID {
 		return nil, nil
@@ -67,9 +112,10 @@ func (r *CategoryRepository) GetCategoryByUUID(ctx context.Context, uuid string)
 		WHERE 
 			deleted_at IS NULL
 			AND uuid = $1
+			AND store_id = $2
 	`
 
-	row := r.db.QueryRowContext(ctx, query, uuid)
+	row := r.db.QueryRowContext(ctx, query, uuid, storeID)
 
 	var c model.Category
 	err := row.Scan(&c.ID, &c.UUID, &c.Name, &c.Description)
@@ -84,9 +130,9 @@ func (r *CategoryRepository) GetCategoryByUUID(ctx context.Context, uuid string)
 	return &c, nil
 }
 
-func (r *CategoryRepository) CreateCategory(ctx context.Context, c model.Category) error {
-	query := "INSERT INTO categories (uuid, name, description) VALUES ($1, $2, $3)"
-	_, err := r.db.ExecContext(ctx, query, c.UUID, c.Name, c.Description)
+func (r *categoryRepository) CreateCategory(ctx context.Context, storeID int64, c model.Category) error {
+	query := "INSERT INTO categories (uuid, name, description, store_id) VALUES ($1, $2, $3, $4)"
+	_, err := r.db.ExecContext(ctx, query, c.UUID, c.Name, c.Description, storeID)
 	if err != nil {
 		fmt.Println("repository.category.CreateCategory() Exec Error: ", err.Error())
 	}
@@ -94,22 +140,213 @@ func (r *CategoryRepository) CreateCategory(ctx context.Context, c model.Categor
 	return err
 }
 
-func (r *CategoryRepository) UpdateCategory(ctx context.Context, c model.Category) error {
-	query := "UPDATE categories SET name = $1, description = $2, updated_at = NOW() WHERE uuid = $3"
-	_, err := r.db.ExecContext(ctx, query, c.Name, c.Description, c.UUID)
+func (r *categoryRepository) UpdateCategory(ctx context.Context, storeID int64, c model.Category, entry model.AuditEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		fmt.Println("repository.category.UpdateCategory() Begin Error: ", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE categories SET name = $1, description = $2, updated_at = " + r.dialect.Now() + " WHERE uuid = $3 AND store_id = $4"
+	_, err = tx.ExecContext(ctx, query, c.Name, c.Description, c.UUID, storeID)
 	if err != nil {
 		fmt.Println("repository.category.UpdateCategory() Exec Error: ", err.Error())
+		return err
+	}
+
+	err = recordAuditEntry(ctx, tx, storeID, &entry)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		fmt.Println("repository.category.UpdateCategory() Commit Error: ", err.Error())
 	}
 
 	return err
 }
 
-func (r *CategoryRepository) DeleteCategory(ctx context.Context, uuid string) error {
-	query := "UPDATE categories SET deleted_at = NOW() WHERE uuid = $1"
-	_, err := r.db.ExecContext(ctx, query, uuid)
+func (r *categoryRepository) DeleteCategory(ctx context.Context, storeID int64, uuid string, entry model.AuditEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		fmt.Println("repository.category.DeleteCategory() Begin Error: ", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE categories SET deleted_at = $1 WHERE uuid = $2 AND store_id = $3 AND deleted_at IS NULL"
+	result, err := tx.ExecContext(ctx, query, time.Now().UTC(), uuid, storeID)
 	if err != nil {
 		fmt.Println("repository.category.DeleteCategory() Exec Error: ", err.Error())
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrCategoryNotFound
+	}
+
+	err = recordAuditEntry(ctx, tx, storeID, &entry)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		fmt.Println("repository.category.DeleteCategory() Commit Error: ", err.Error())
 	}
 
 	return err
 }
+
+func (r *categoryRepository) GetDeletedCategories(ctx context.Context, storeID int64, filter TrashFilter) ([]model.Category, error) {
+	query := "SELECT id, uuid, name, description, deleted_at FROM categories WHERE deleted_at IS NOT NULL AND store_id = $1"
+
+	args := []interface{}{storeID}
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.DeletedBefore != nil {
+		query += " AND deleted_at < " + addArg(filter.DeletedBefore.UTC())
+	}
+	if filter.BeforeID > 0 {
+		query += " AND id < " + addArg(filter.BeforeID)
+	}
+
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT " + addArg(filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		fmt.Println("repository.category.GetDeletedCategories() Query Error: ", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := make([]model.Category, 0)
+	for rows.Next() {
+		var c model.Category
+		err := rows.Scan(&c.ID, &c.UUID, &c.Name, &c.Description, &c.DeletedAt)
+		if err != nil {
+			fmt.Println("repository.category.GetDeletedCategories() Scan Error: ", err.Error())
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+func (r *categoryRepository) GetDeletedCategoryByUUID(ctx context.Context, storeID int64, uuid string) (*model.Category, error) {
+	if !helper.IsValidUUID(uuid) {
+		return nil, nil
+	}
+
+	query := "SELECT id, uuid, name, description, deleted_at FROM categories WHERE deleted_at IS NOT NULL AND uuid = $1 AND store_id = $2"
+
+	var c model.Category
+	err := r.db.QueryRowContext(ctx, query, uuid, storeID).Scan(&c.ID, &c.UUID, &c.Name, &c.Description, &c.DeletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		fmt.Println("repository.category.GetDeletedCategoryByUUID() Scan Error: ", err.Error())
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (r *categoryRepository) RestoreCategory(ctx context.Context, storeID int64, uuid string, entry model.AuditEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		fmt.Println("repository.category.RestoreCategory() Begin Error: ", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE categories SET deleted_at = NULL, updated_at = " + r.dialect.Now() + " WHERE uuid = $1 AND store_id = $2 AND deleted_at IS NOT NULL"
+	result, err := tx.ExecContext(ctx, query, uuid, storeID)
+	if err != nil {
+		fmt.Println("repository.category.RestoreCategory() Exec Error: ", err.Error())
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrCategoryNotFound
+	}
+
+	err = recordAuditEntry(ctx, tx, storeID, &entry)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		fmt.Println("repository.category.RestoreCategory() Commit Error: ", err.Error())
+	}
+
+	return err
+}
+
+func (r *categoryRepository) PurgeCategories(ctx context.Context, storeID int64, entries []model.AuditEntry) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		fmt.Println("repository.category.PurgeCategories() Begin Error: ", err.Error())
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var purged int64
+	for _, entry := range entries {
+		var id int64
+		query := "SELECT id FROM categories WHERE uuid = $1 AND store_id = $2 AND deleted_at IS NOT NULL"
+		err := tx.QueryRowContext(ctx, query, entry.EntityID, storeID).Scan(&id)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			fmt.Println("repository.category.PurgeCategories() Query Error: ", err.Error())
+			return 0, err
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE products SET category_id = NULL WHERE category_id = $1", id)
+		if err != nil {
+			fmt.Println("repository.category.PurgeCategories() Update Error: ", err.Error())
+			return 0, err
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM categories WHERE id = $1", id)
+		if err != nil {
+			fmt.Println("repository.category.PurgeCategories() Delete Error: ", err.Error())
+			return 0, err
+		}
+
+		err = recordAuditEntry(ctx, tx, storeID, &entry)
+		if err != nil {
+			return 0, err
+		}
+		purged++
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		fmt.Println("repository.category.PurgeCategories() Commit Error: ", err.Error())
+		return 0, err
+	}
+
+	return purged, nil
+}
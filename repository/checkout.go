@@ -3,57 +3,194 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fendi/modul-03-task/database"
 	"fendi/modul-03-task/helper"
 	"fendi/modul-03-task/model"
 	"fendi/modul-03-task/transport"
 	"fmt"
+	"strings"
 	"time"
 )
 
-type CheckoutRepository struct {
-	db *sql.DB
+type checkoutRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
 }
 
-func NewCheckoutRepository(db *sql.DB) *CheckoutRepository {
-	return &CheckoutRepository{db: db}
+func NewCheckoutRepository(db *sql.DB, dialect database.Dialect) CheckoutRepository {
+	return &checkoutRepository{db: db, dialect: dialect}
 }
 
-func (r *CheckoutRepository) CreateCheckoutTransaction(ctx context.Context, req transport.CheckoutRequest) (*model.Transaction, error) {
-	tx, err := r.db.Begin()
+// CheckoutPlan is the outcome of matching checkout items against current stock.
+type CheckoutPlan struct {
+	Details     []model.TransactionDetail
+	TotalAmount model.Money
+	// StockDeltas holds the quantity to take from each stock-tracked product, keyed by product ID.
+	StockDeltas map[int64]int64
+	Issues      []model.CheckoutIssue
+}
+
+// PlanCheckout matches the requested items against the given products. Items that
+// are missing, sold out or have an invalid quantity are reported as issues; in
+// non-strict mode quantities above the remaining stock are clamped to what is left,
+// in strict mode they are reported as issues too. Repeated items draw from the same
+// remaining stock. A line total or grand total above model.MaxMoney fails the whole
+// plan with ErrAmountTooLarge.
+func PlanCheckout(items []transport.CheckoutItem, products []model.Product, strict bool) (CheckoutPlan, error) {
+	plan := CheckoutPlan{StockDeltas: make(map[int64]int64)}
+
+	for idx, item := range items {
+		product, ok := findCheckoutProduct(item, products)
+		if !ok {
+			plan.Issues = append(plan.Issues, model.CheckoutIssue{
+				ProductID: item.Reference(),
+				Reason:    model.CheckoutIssueNotFound,
+				Requested: item.Quantity,
+			})
+			continue
+		}
+
+		if item.Quantity <= 0 {
+			plan.Issues = append(plan.Issues, model.CheckoutIssue{
+				ProductID: item.Reference(),
+				Reason:    model.CheckoutIssueInvalidQuantity,
+				Requested: item.Quantity,
+			})
+			continue
+		}
+
+		itemQty := item.Quantity
+		if product.Stock != nil {
+			remaining := *product.Stock - plan.StockDeltas[product.ID]
+			if remaining <= 0 {
+				plan.Issues = append(plan.Issues, model.CheckoutIssue{
+					ProductID: item.Reference(),
+					Reason:    model.CheckoutIssueOutOfStock,
+					Requested: item.Quantity,
+					Available: &remaining,
+				})
+				continue
+			}
+
+			if itemQty > remaining {
+				plan.Issues = append(plan.Issues, model.CheckoutIssue{
+					ProductID: item.Reference(),
+					Reason:    model.CheckoutIssueInsufficientStock,
+					Requested: item.Quantity,
+					Available: &remaining,
+				})
+				if strict {
+					continue
+				}
+				itemQty = remaining
+			}
+			plan.StockDeltas[product.ID] += itemQty
+		}
+
+		var price model.Money
+		if product.Price != nil {
+			price = *product.Price
+		}
+		subTotal, err := price.Mul(itemQty)
+		if err != nil || subTotal > model.MaxMoney {
+			return CheckoutPlan{}, NewValidationError(ErrAmountTooLarge, fmt.Sprintf("items[%d].quantity", idx), fmt.Sprintf("line total must not exceed %s", model.MaxMoney.Rupiah()))
+		}
+		plan.TotalAmount, err = plan.TotalAmount.Add(subTotal)
+		if err != nil || plan.TotalAmount > model.MaxMoney {
+			return CheckoutPlan{}, NewValidationError(ErrAmountTooLarge, "items", fmt.Sprintf("total must not exceed %s", model.MaxMoney.Rupiah()))
+		}
+
+		plan.Details = append(plan.Details, model.TransactionDetail{
+			ProductID:   product.ID,
+			ProductUUID: product.UUID,
+			ProductName: product.Name,
+			Price:       price,
+			Quantity:    itemQty,
+			SubTotal:    subTotal,
+		})
+	}
+
+	return plan, nil
+}
+
+// findCheckoutProduct returns the product item references by UUID, SKU or barcode.
+func findCheckoutProduct(item transport.CheckoutItem, products []model.Product) (model.Product, bool) {
+	for _, p := range products {
+		switch {
+		case item.ID != "" && p.UUID == item.ID,
+			item.SKU != "" && p.SKU == item.SKU,
+			item.Barcode != "" && p.Barcode != nil && *p.Barcode == item.Barcode:
+			return p, true
+		}
+	}
+
+	return model.Product{}, false
+}
+
+// Validate reports whether the plan can be committed. Strict checkouts fail on any
+// issue, otherwise only when nothing is left to sell.
+func (p CheckoutPlan) Validate(strict bool) error {
+	if strict && len(p.Issues) > 0 {
+		return &StockError{Issues: p.Issues}
+	}
+	for _, issue := range p.Issues {
+		fmt.Printf("Checkout item %s skipped or reduced: %s\n", issue.ProductID, issue.Reason)
+	}
+	if len(p.Details) == 0 {
+		return ErrNoProductsFound
+	}
+
+	return nil
+}
+
+func (r *checkoutRepository) CreateCheckoutTransaction(ctx context.Context, storeID int64, req transport.CheckoutRequest, strict bool) (*model.Transaction, error) {
+	var products []model.Product
+	if len(req.Items) == 0 {
+		return nil, NewValidationError(ErrInvalidRequest, "items", "must contain at least one item")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		fmt.Print("Failed to begin transaction: ", err)
 		return nil, err
 	}
 	defer tx.Rollback()
 
-	var productUUIDs []string
-	var products []model.Product
+	// Items may reference their product by UUID, SKU or barcode.
+	var uuids, skus, barcodes []interface{}
 	for _, item := range req.Items {
-		productUUIDs = append(productUUIDs, item.ID)
-	}
-
-	if len(productUUIDs) == 0 {
-		return nil, fmt.Errorf("no items provided for checkout")
+		switch {
+		case item.SKU != "":
+			skus = append(skus, item.SKU)
+		case item.Barcode != "":
+			barcodes = append(barcodes, item.Barcode)
+		default:
+			uuids = append(uuids, item.ID)
+		}
 	}
 
-	// Build placeholders for IN clause (PostgreSQL style: $1, $2, $3...)
-	placeholders := ""
-	args := make([]interface{}, len(productUUIDs))
-	for i, uuid := range productUUIDs {
-		if i > 0 {
-			placeholders += ", "
+	args := []interface{}{storeID}
+	var conds []string
+	for _, ref := range []struct {
+		column string
+		values []interface{}
+	}{{"uuid", uuids}, {"sku", skus}, {"barcode", barcodes}} {
+		if len(ref.values) == 0 {
+			continue
 		}
-		placeholders += fmt.Sprintf("$%d", i+1)
-		args[i] = uuid
+		conds = append(conds, fmt.Sprintf("%s IN (%s)", ref.column, placeholders(len(args)+1, len(ref.values))))
+		args = append(args, ref.values...)
 	}
 
+	// Lock the product rows in ID order so concurrent checkouts of the same products
+	// wait for each other instead of reading the same stock (or deadlocking).
 	var query string
-	query = fmt.Sprintf("SELECT id, uuid, name, stock, price FROM products WHERE uuid IN (%s)", placeholders)
+	query = fmt.Sprintf(
+		"SELECT id, uuid, COALESCE(sku, ''), barcode, name, stock, price FROM products WHERE (%s) AND store_id = $1 AND deleted_at IS NULL ORDER BY id%s",
+		strings.Join(conds, " OR "), r.dialect.ForUpdate(),
+	)
 	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("no products found for the given UUIDs")
-		}
 		fmt.Print("Failed to query products: ", err)
 		return nil, err
 	}
@@ -61,75 +198,71 @@ func (r *CheckoutRepository) CreateCheckoutTransaction(ctx context.Context, req
 
 	for rows.Next() {
 		var p model.Product
-		if err := rows.Scan(&p.ID, &p.UUID, &p.Name, &p.Stock, &p.Price); err != nil {
+		if err := rows.Scan(&p.ID, &p.UUID, &p.SKU, &p.Barcode, &p.Name, &p.Stock, &p.Price); err != nil {
 			fmt.Print("Failed to scan product row: ", err)
 			return nil, err
 		}
 		products = append(products, p)
 	}
+	if err := rows.Err(); err != nil {
+		fmt.Print("Failed to read product rows: ", err)
+		return nil, err
+	}
 
-	var totalAmount float64
-	var transactionDetails []model.TransactionDetail
+	plan, err := PlanCheckout(req.Items, products, strict)
+	if err != nil {
+		return nil, err
+	}
+	err = plan.Validate(strict)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, item := range req.Items {
-		var product model.Product
-		for _, p := range products {
-			if p.UUID == item.ID {
-				product = p
-				break
-			}
-		}
+	var transactionUUID string
+	transactionUUID = helper.GenerateUUID()
 
-		if product.UUID == "" {
-			fmt.Printf("Product with UUID %s not found\n", item.ID)
+	for _, product := range products {
+		delta, ok := plan.StockDeltas[product.ID]
+		if !ok {
 			continue
 		}
 
-		itemQty := item.Quantity
-		if product.Stock != nil {
-			if *product.Stock <= 0 {
-				fmt.Printf("Product with UUID %s is out of stock\n", item.ID)
-				continue
-			}
-
-			if itemQty > *product.Stock {
-				itemQty = *product.Stock
-			}
-			newStock := *product.Stock - itemQty
-
-			query = "UPDATE products SET stock = $1 WHERE uuid = $2"
-			_, err := tx.ExecContext(ctx, query, newStock, product.UUID)
-			if err != nil {
-				fmt.Print("Failed to update product stock: ", err)
-				return nil, err
+		// The stock guard makes the decrement atomic even without row locks.
+		var balance int64
+		query = "UPDATE products SET stock = stock - $1 WHERE id = $2 AND stock >= $1 RETURNING stock"
+		err := tx.QueryRowContext(ctx, query, delta, product.ID).Scan(&balance)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, &StockError{Issues: []model.CheckoutIssue{{
+					ProductID: product.UUID,
+					Reason:    model.CheckoutIssueInsufficientStock,
+					Requested: delta,
+				}}}
 			}
+			fmt.Print("Failed to update product stock: ", err)
+			return nil, err
 		}
 
-		var price, subTotal float64
-		price = 0
-		if product.Price != nil {
-			price = *product.Price
-		}
-		subTotal = float64(itemQty) * price
-		totalAmount += subTotal
-
-		transactionDetails = append(transactionDetails, model.TransactionDetail{
-			ProductID:   product.ID,
-			ProductUUID: product.UUID,
-			ProductName: product.Name,
-			Price:       price,
-			Quantity:    itemQty,
-			SubTotal:    subTotal,
+		err = recordStockMovement(ctx, tx, &model.StockMovement{
+			ProductID: product.ID,
+			Type:      model.StockMovementSale,
+			Delta:     -delta,
+			Balance:   balance,
+			Reference: transactionUUID,
 		})
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	totalAmount := plan.TotalAmount
+	transactionDetails := plan.Details
+
 	var transactionID int64
-	var transactionUUID string
 	var currentTime time.Time
-	transactionUUID = helper.GenerateUUID()
-	currentTime = time.Now()
-	query = "INSERT INTO transactions (uuid, total_amount, purchased_at) VALUES ($1, $2, $3) RETURNING id"
-	err = tx.QueryRowContext(ctx, query, transactionUUID, totalAmount, currentTime).Scan(&transactionID)
+	currentTime = time.Now().UTC()
+	query = "INSERT INTO transactions (uuid, total_amount, purchased_at, store_id) VALUES ($1, $2, $3, $4) RETURNING id"
+	err = tx.QueryRowContext(ctx, query, transactionUUID, totalAmount, currentTime, storeID).Scan(&transactionID)
 	if err != nil {
 		fmt.Print("Failed to insert transaction: ", err)
 		return nil, err
@@ -145,6 +278,7 @@ func (r *CheckoutRepository) CreateCheckoutTransaction(ctx context.Context, req
 		}
 
 		transactionDetails[i].ID = trxDetailID
+		transactionDetails[i].TransactionID = transactionID
 	}
 
 	err = tx.Commit()
@@ -164,3 +298,153 @@ func (r *CheckoutRepository) CreateCheckoutTransaction(ctx context.Context, req
 
 	return &transaction, nil
 }
+
+func (r *checkoutRepository) GetAllTransaction(ctx context.Context, storeID int64, filter TransactionFilter) ([]model.Transaction, error) {
+	query :=
+		`SELECT 
+			t.id, t.uuid, t.total_amount, t.purchased_at
+		FROM transactions t
+		WHERE t.store_id = $1`
+
+	args := []interface{}{storeID}
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.From != nil {
+		query += " AND t.purchased_at >= " + addArg(filter.From.UTC())
+	}
+	if filter.To != nil {
+		query += " AND t.purchased_at < " + addArg(filter.To.UTC())
+	}
+	if filter.MinAmount != nil {
+		query += " AND t.total_amount >= " + addArg(*filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query += " AND t.total_amount <= " + addArg(*filter.MaxAmount)
+	}
+	if filter.ProductUUID != "" {
+		query += ` AND EXISTS (
+			SELECT 1 FROM transaction_details td
+			JOIN products p ON p.id = td.product_id
+			WHERE td.transaction_id = t.id AND p.uuid = ` + addArg(filter.ProductUUID) + `)`
+	}
+	if filter.BeforeID > 0 {
+		query += " AND t.id < " + addArg(filter.BeforeID)
+	}
+
+	query += " ORDER BY t.id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT " + addArg(filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		fmt.Println("repository.checkout.GetAllTransaction() Query Error: ", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := make([]model.Transaction, 0)
+	for rows.Next() {
+		var t model.Transaction
+		err := rows.Scan(&t.ID, &t.UUID, &t.TotalAmount, &t.PurchasedAt)
+		if err != nil {
+			fmt.Println("repository.checkout.GetAllTransaction() Scan Error: ", err.Error())
+			return nil, err
+		}
+		transactions = append(transactions, t)
+	}
+	if err := rows.Err(); err != nil {
+		fmt.Println("repository.checkout.GetAllTransaction() Rows Error: ", err.Error())
+		return nil, err
+	}
+
+	err = r.attachDetails(ctx, transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (r *checkoutRepository) GetTransactionByUUID(ctx context.Context, storeID int64, uuid string) (*model.Transaction, error) {
+	isValidUUID := helper.IsValidUUID(uuid)
+	if !isValidUUID {
+		return nil, nil
+	}
+
+	query := `SELECT 
+			t.id, t.uuid, t.total_amount, t.purchased_at
+		FROM transactions t
+		WHERE t.uuid = $1 AND t.store_id = $2`
+
+	var t model.Transaction
+	err := r.db.QueryRowContext(ctx, query, uuid, storeID).Scan(&t.ID, &t.UUID, &t.TotalAmount, &t.PurchasedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		fmt.Println("repository.checkout.GetTransactionByUUID() Scan Error: ", err.Error())
+		return nil, err
+	}
+
+	transactions := []model.Transaction{t}
+	err = r.attachDetails(ctx, transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transactions[0], nil
+}
+
+// attachDetails loads the detail lines and refunds of the given transactions.
+func (r *checkoutRepository) attachDetails(ctx context.Context, transactions []model.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(transactions))
+	index := make(map[int64]int, len(transactions))
+	for i, t := range transactions {
+		args[i] = t.ID
+		index[t.ID] = i
+		transactions[i].Details = make([]model.TransactionDetail, 0)
+	}
+
+	query := fmt.Sprintf(
+		`SELECT 
+			td.id, td.transaction_id, td.product_id, COALESCE(p.uuid, ''), td.name, td.price, td.quantity, td.subtotal,
+			COALESCE((SELECT SUM(rd.quantity) FROM refund_details rd WHERE rd.transaction_detail_id = td.id), 0)
+		FROM transaction_details td
+		LEFT JOIN products p ON p.id = td.product_id
+		WHERE td.transaction_id IN (%s)
+		ORDER BY td.id ASC`,
+		placeholders(1, len(args)),
+	)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		fmt.Println("repository.checkout.attachDetails() Query Error: ", err.Error())
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d model.TransactionDetail
+		err := rows.Scan(&d.ID, &d.TransactionID, &d.ProductID, &d.ProductUUID, &d.ProductName, &d.Price, &d.Quantity, &d.SubTotal, &d.RefundedQuantity)
+		if err != nil {
+			fmt.Println("repository.checkout.attachDetails() Scan Error: ", err.Error())
+			return err
+		}
+
+		i := index[d.TransactionID]
+		transactions[i].Details = append(transactions[i].Details, d)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return r.attachRefunds(ctx, transactions)
+}
@@ -0,0 +1,107 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fendi/modul-03-task/database"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"fendi/modul-03-task/repository/memory"
+	"fendi/modul-03-task/transport"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// defaultStoreID is the ID of the Main store of a fresh database.
+const defaultStoreID = 1
+
+// TestConcurrentCheckoutNeverOversells runs more single-unit strict checkouts than
+// there is stock and checks that exactly the stocked units are sold.
+func TestConcurrentCheckoutNeverOversells(t *testing.T) {
+	const (
+		buyers = 40
+		stock  = 7
+	)
+
+	type repos struct {
+		category repository.CategoryRepository
+		product  repository.ProductRepository
+		checkout repository.CheckoutRepository
+	}
+
+	backends := map[string]func(t *testing.T) repos{
+		"memory": func(t *testing.T) repos {
+			store := memory.NewStore()
+			return repos{memory.NewCategoryRepository(store), memory.NewProductRepository(store), memory.NewCheckoutRepository(store)}
+		},
+		"sqlite": func(t *testing.T) repos {
+			db, dialect, err := database.InitDB("sqlite://" + filepath.Join(t.TempDir(), "test.db"))
+			if err != nil {
+				t.Fatalf("InitDB() error = %v", err)
+			}
+			t.Cleanup(func() { db.Close() })
+			if _, err := database.MigrateUp(db, dialect); err != nil {
+				t.Fatalf("MigrateUp() error = %v", err)
+			}
+			return repos{repository.NewCategoryRepository(db, dialect), repository.NewProductRepository(db, dialect), repository.NewCheckoutRepository(db, dialect)}
+		},
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			r := open(t)
+
+			category := model.Category{UUID: helper.GenerateUUID(), Name: "Test"}
+			if err := r.category.CreateCategory(ctx, defaultStoreID, category); err != nil {
+				t.Fatalf("CreateCategory() error = %v", err)
+			}
+			created, err := r.category.GetCategoryByUUID(ctx, defaultStoreID, category.UUID)
+			if err != nil {
+				t.Fatalf("GetCategoryByUUID() error = %v", err)
+			}
+
+			initial, price := int64(stock), model.Money(2500*model.MoneyScale)
+			product := model.Product{UUID: helper.GenerateUUID(), SKU: "TEST-1", Name: "Test", Stock: &initial, Price: &price, Category: created}
+			if err := r.product.CreateProduct(ctx, defaultStoreID, product); err != nil {
+				t.Fatalf("CreateProduct() error = %v", err)
+			}
+
+			req := transport.CheckoutRequest{Items: []transport.CheckoutItem{{ID: product.UUID, Quantity: 1}}}
+			errs := make([]error, buyers)
+			var wg sync.WaitGroup
+			for i := range buyers {
+				wg.Go(func() {
+					_, errs[i] = r.checkout.CreateCheckoutTransaction(ctx, defaultStoreID, req, true)
+				})
+			}
+			wg.Wait()
+
+			var sold, rejected int
+			for _, err := range errs {
+				var stockErr *repository.StockError
+				switch {
+				case err == nil:
+					sold++
+				case errors.As(err, &stockErr):
+					rejected++
+				default:
+					t.Errorf("CreateCheckoutTransaction() unexpected error = %v", err)
+				}
+			}
+			if sold != stock || rejected != buyers-stock {
+				t.Errorf("sold %d and rejected %d checkouts, want %d and %d", sold, rejected, stock, buyers-stock)
+			}
+
+			got, err := r.product.GetProductByUUID(ctx, defaultStoreID, product.UUID)
+			if err != nil {
+				t.Fatalf("GetProductByUUID() error = %v", err)
+			}
+			if got.Stock == nil || *got.Stock != 0 {
+				t.Errorf("final stock = %v, want 0", got.Stock)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"errors"
+	"fendi/modul-03-task/model"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidRequest is the kind of ValidationError returned for request fields that
+// are present but not acceptable.
+var ErrInvalidRequest = errors.New("invalid request")
+
+// ErrAmountTooLarge is the kind of ValidationError returned when an amount worked
+// out from a request, such as a checkout total, exceeds model.MaxMoney.
+var ErrAmountTooLarge = errors.New("amount too large")
+
+// ErrNoProductsFound is returned when none of the checkout items can be sold.
+var ErrNoProductsFound = errors.New("no products found for the given items")
+
+// ErrTransactionNotFound is returned when a transaction UUID does not exist.
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// ErrNothingToRefund is returned when every item of a transaction has already been reversed.
+var ErrNothingToRefund = errors.New("nothing left to refund on this transaction")
+
+// ErrInvalidRefund is wrapped by errors describing refund items that cannot be refunded.
+var ErrInvalidRefund = errors.New("invalid refund")
+
+// ErrProductNotFound is returned when a product UUID does not exist.
+var ErrProductNotFound = errors.New("product not found")
+
+// ErrCategoryNotFound is returned when a category UUID does not exist.
+var ErrCategoryNotFound = errors.New("category not found")
+
+// ErrDuplicateSKU is returned when a SKU is already used by another product,
+// including a deleted one.
+var ErrDuplicateSKU = errors.New("sku already in use")
+
+// ErrDuplicateBarcode is returned when a barcode is already used by another product,
+// including a deleted one.
+var ErrDuplicateBarcode = errors.New("barcode already in use")
+
+// ErrStockNotTracked is returned when adjusting stock of a product with unlimited stock.
+var ErrStockNotTracked = errors.New("product does not track stock")
+
+// ErrInvalidStockAdjustment is the kind of ValidationError returned for stock
+// adjustments that are malformed or would take stock above model.MaxStock.
+var ErrInvalidStockAdjustment = errors.New("invalid stock adjustment")
+
+// ErrNegativeStock is returned when a stock adjustment would leave a negative balance.
+var ErrNegativeStock = errors.New("stock adjustment would make stock negative")
+
+// ErrUserNotFound is returned when a user UUID does not exist.
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrAPIKeyNotFound is returned when an API key UUID does not exist.
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// ErrStoreNotFound is returned when a store UUID does not exist.
+var ErrStoreNotFound = errors.New("store not found")
+
+// StockError is returned by a strict checkout when one or more items cannot be
+// fulfilled in full. Nothing is written when it is returned.
+type StockError struct {
+	Issues []model.CheckoutIssue
+}
+
+func (e *StockError) Error() string {
+	return fmt.Sprintf("checkout rejected: %d item(s) cannot be fulfilled", len(e.Issues))
+}
+
+// FieldError describes what is wrong with a single request field.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationError reports one or more unacceptable request fields. Err is the kind of
+// failure, such as ErrInvalidRequest or ErrInvalidRefund, and is what errors.Is matches.
+type ValidationError struct {
+	Err    error
+	Fields []FieldError
+}
+
+// NewValidationError creates a ValidationError of the given kind for a single field.
+func NewValidationError(kind error, field, message string) *ValidationError {
+	return &ValidationError{Err: kind, Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+" "+f.Message)
+	}
+
+	return e.Err.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fendi/modul-03-task/database"
+	"fendi/modul-03-task/model"
+	"fmt"
+)
+
+type idempotencyRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+func NewIdempotencyRepository(db *sql.DB, dialect database.Dialect) IdempotencyRepository {
+	return &idempotencyRepository{db: db, dialect: dialect}
+}
+
+func (r *idempotencyRepository) ReserveIdempotencyKey(ctx context.Context, rec model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	query := `INSERT INTO idempotency_keys (scope, idem_key, fingerprint, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (scope, idem_key) DO NOTHING`
+	result, err := r.db.ExecContext(ctx, query, rec.Scope, rec.Key, rec.Fingerprint, rec.CreatedAt)
+	if err != nil {
+		fmt.Println("repository.idempotency.ReserveIdempotencyKey() Exec Error: ", err.Error())
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected > 0 {
+		return nil, nil
+	}
+
+	query = `SELECT
+			scope, idem_key, fingerprint, status_code, content_type, response_body, created_at, completed_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND idem_key = $2`
+
+	var existing model.IdempotencyRecord
+	err = r.db.QueryRowContext(ctx, query, rec.Scope, rec.Key).Scan(
+		&existing.Scope, &existing.Key, &existing.Fingerprint, &existing.StatusCode,
+		&existing.ContentType, &existing.ResponseBody, &existing.CreatedAt, &existing.CompletedAt,
+	)
+	if err != nil {
+		fmt.Println("repository.idempotency.ReserveIdempotencyKey() Scan Error: ", err.Error())
+		return nil, err
+	}
+
+	return &existing, nil
+}
+
+func (r *idempotencyRepository) CompleteIdempotencyKey(ctx context.Context, rec model.IdempotencyRecord) error {
+	query := `UPDATE idempotency_keys
+		SET status_code = $1, content_type = $2, response_body = $3, completed_at = $4
+		WHERE scope = $5 AND idem_key = $6`
+	_, err := r.db.ExecContext(ctx, query, rec.StatusCode, rec.ContentType, rec.ResponseBody, rec.CompletedAt, rec.Scope, rec.Key)
+	if err != nil {
+		fmt.Println("repository.idempotency.CompleteIdempotencyKey() Exec Error: ", err.Error())
+	}
+
+	return err
+}
+
+func (r *idempotencyRepository) DeleteIdempotencyKey(ctx context.Context, scope, key string) error {
+	query := "DELETE FROM idempotency_keys WHERE scope = $1 AND idem_key = $2"
+	_, err := r.db.ExecContext(ctx, query, scope, key)
+	if err != nil {
+		fmt.Println("repository.idempotency.DeleteIdempotencyKey() Exec Error: ", err.Error())
+	}
+
+	return err
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"maps"
+)
+
+type auditRepository struct {
+	store *Store
+}
+
+func NewAuditRepository(store *Store) repository.AuditRepository {
+	return &auditRepository{store: store}
+}
+
+func (r *auditRepository) GetAuditEntries(ctx context.Context, storeID int64, filter repository.AuditFilter) ([]model.AuditEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entries := make([]model.AuditEntry, 0)
+	for i := len(r.store.auditEntries) - 1; i >= 0; i-- {
+		e := r.store.auditEntries[i]
+		if e.StoreID != storeID {
+			continue
+		}
+		if filter.EntityType != "" && e.EntityType != filter.EntityType {
+			continue
+		}
+		if filter.EntityID != "" && e.EntityID != filter.EntityID {
+			continue
+		}
+		if filter.Action != "" && e.Action != filter.Action {
+			continue
+		}
+		if filter.From != nil && e.CreatedAt.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !e.CreatedAt.Before(*filter.To) {
+			continue
+		}
+		if filter.BeforeID > 0 && e.ID >= filter.BeforeID {
+			continue
+		}
+
+		entry := e.AuditEntry
+		entry.Changes = maps.Clone(e.Changes)
+		entries = append(entries, entry)
+		if filter.Limit > 0 && len(entries) == filter.Limit {
+			break
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,219 @@
+package memory
+
+import (
+	"context"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"slices"
+	"strings"
+	"time"
+)
+
+type categoryRepository struct {
+	store *Store
+}
+
+func NewCategoryRepository(store *Store) repository.CategoryRepository {
+	return &categoryRepository{store: store}
+}
+
+func (r *categoryRepository) GetAllCategory(ctx context.Context, storeID int64, filter repository.CategoryFilter) ([]model.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	categories := paginate(r.store.filterCategories(storeID, filter),
+		func(c model.Category) interface{} { return repository.CategorySortKey(c, filter.Sort) },
+		func(c model.Category) int64 { return c.ID },
+		filter.Desc, filter.After, filter.Offset, filter.Limit)
+
+	return categories, nil
+}
+
+func (r *categoryRepository) CountCategory(ctx context.Context, storeID int64, filter repository.CategoryFilter) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return int64(len(r.store.filterCategories(storeID, filter))), nil
+}
+
+// filterCategories returns the live categories of the store matching filter,
+// unsorted. The caller must hold the lock.
+func (s *Store) filterCategories(storeID int64, filter repository.CategoryFilter) []model.Category {
+	keyword := strings.ToLower(filter.Keyword)
+
+	categories := make([]model.Category, 0)
+	for _, c := range s.categories {
+		if c.StoreID != storeID || c.DeletedAt != nil {
+			continue
+		}
+		if len(keyword) > 0 && !strings.Contains(strings.ToLower(c.Name), keyword) {
+			continue
+		}
+		categories = append(categories, toCategoryModel(c))
+	}
+
+	return categories
+}
+
+func (r *categoryRepository) GetCategoryByUUID(ctx context.Context, storeID int64, uuid string) (*model.Category, error) {
+	isValidUUID := helper.IsValidUUID(uuid)
+	if !isValidUUID {
+		return nil, nil
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, c := range r.store.categories {
+		if c.StoreID == storeID && c.UUID == uuid && c.DeletedAt == nil {
+			category := toCategoryModel(c)
+			return &category, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *categoryRepository) CreateCategory(ctx context.Context, storeID int64, c model.Category) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	r.store.lastCategoryID++
+	r.store.categories = append(r.store.categories, &categoryRecord{
+		ID:          r.store.lastCategoryID,
+		StoreID:     storeID,
+		UUID:        c.UUID,
+		Name:        c.Name,
+		Description: cloneString(c.Description),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+
+	return nil
+}
+
+func (r *categoryRepository) UpdateCategory(ctx context.Context, storeID int64, c model.Category, entry model.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, record := range r.store.categories {
+		if record.StoreID == storeID && record.UUID == c.UUID {
+			record.Name = c.Name
+			record.Description = cloneString(c.Description)
+			record.UpdatedAt = time.Now()
+		}
+	}
+	r.store.recordAuditEntry(storeID, entry)
+
+	return nil
+}
+
+func (r *categoryRepository) DeleteCategory(ctx context.Context, storeID int64, uuid string, entry model.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now().UTC()
+	for _, record := range r.store.categories {
+		if record.StoreID == storeID && record.UUID == uuid && record.DeletedAt == nil {
+			record.DeletedAt = &now
+			r.store.recordAuditEntry(storeID, entry)
+			return nil
+		}
+	}
+
+	return repository.ErrCategoryNotFound
+}
+
+func (r *categoryRepository) GetDeletedCategories(ctx context.Context, storeID int64, filter repository.TrashFilter) ([]model.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	categories := make([]model.Category, 0)
+	for i := len(r.store.categories) - 1; i >= 0; i-- {
+		c := r.store.categories[i]
+		if c.StoreID != storeID || !inTrash(c.DeletedAt, filter) {
+			continue
+		}
+		if filter.BeforeID > 0 && c.ID >= filter.BeforeID {
+			continue
+		}
+
+		categories = append(categories, toCategoryModel(c))
+		if filter.Limit > 0 && len(categories) == filter.Limit {
+			break
+		}
+	}
+
+	return categories, nil
+}
+
+func (r *categoryRepository) GetDeletedCategoryByUUID(ctx context.Context, storeID int64, uuid string) (*model.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, c := range r.store.categories {
+		if c.StoreID == storeID && c.UUID == uuid && c.DeletedAt != nil {
+			category := toCategoryModel(c)
+			return &category, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *categoryRepository) RestoreCategory(ctx context.Context, storeID int64, uuid string, entry model.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, record := range r.store.categories {
+		if record.StoreID == storeID && record.UUID == uuid && record.DeletedAt != nil {
+			record.DeletedAt = nil
+			record.UpdatedAt = time.Now()
+			r.store.recordAuditEntry(storeID, entry)
+			return nil
+		}
+	}
+
+	return repository.ErrCategoryNotFound
+}
+
+func (r *categoryRepository) PurgeCategories(ctx context.Context, storeID int64, entries []model.AuditEntry) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var purged int64
+	for _, entry := range entries {
+		i := slices.IndexFunc(r.store.categories, func(c *categoryRecord) bool {
+			return c.StoreID == storeID && c.UUID == entry.EntityID && c.DeletedAt != nil
+		})
+		if i < 0 {
+			continue
+		}
+
+		id := r.store.categories[i].ID
+		for _, p := range r.store.products {
+			if p.CategoryID != nil && *p.CategoryID == id {
+				p.CategoryID = nil
+			}
+		}
+
+		r.store.categories = slices.Delete(r.store.categories, i, i+1)
+		r.store.recordAuditEntry(storeID, entry)
+		purged++
+	}
+
+	return purged, nil
+}
+
+// toCategoryModel converts a stored category record to model.Category.
+func toCategoryModel(c *categoryRecord) model.Category {
+	return model.Category{
+		ID:          c.ID,
+		UUID:        c.UUID,
+		Name:        c.Name,
+		Description: cloneString(c.Description),
+		DeletedAt:   cloneTime(c.DeletedAt),
+	}
+}
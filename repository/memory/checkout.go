@@ -0,0 +1,228 @@
+package memory
+
+import (
+	"context"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"fendi/modul-03-task/transport"
+	"time"
+)
+
+type checkoutRepository struct {
+	store *Store
+}
+
+func NewCheckoutRepository(store *Store) repository.CheckoutRepository {
+	return &checkoutRepository{store: store}
+}
+
+func (r *checkoutRepository) CreateCheckoutTransaction(ctx context.Context, storeID int64, req transport.CheckoutRequest, strict bool) (*model.Transaction, error) {
+	if len(req.Items) == 0 {
+		return nil, repository.NewValidationError(repository.ErrInvalidRequest, "items", "must contain at least one item")
+	}
+
+	// Holding the write lock for the whole checkout makes the stock updates and
+	// the transaction insert atomic, like the database transaction does.
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Items may reference their product by UUID, SKU or barcode.
+	var products []model.Product
+	for _, item := range req.Items {
+		for _, p := range r.store.products {
+			if p.StoreID != storeID || p.DeletedAt != nil {
+				continue
+			}
+			if (item.SKU != "" && p.SKU == item.SKU) ||
+				(item.Barcode != "" && p.Barcode != nil && *p.Barcode == item.Barcode) ||
+				(item.ID != "" && p.UUID == item.ID) {
+				products = append(products, r.store.toProductModel(p))
+				break
+			}
+		}
+	}
+
+	plan, err := repository.PlanCheckout(req.Items, products, strict)
+	if err != nil {
+		return nil, err
+	}
+	err = plan.Validate(strict)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionUUID := helper.GenerateUUID()
+
+	// Products are walked in request order so ledger entries are deterministic.
+	for _, p := range products {
+		delta, ok := plan.StockDeltas[p.ID]
+		if !ok {
+			continue
+		}
+		delete(plan.StockDeltas, p.ID)
+
+		product := r.store.findProductByID(p.ID)
+		newStock := *product.Stock - delta
+		product.Stock = &newStock
+
+		r.store.recordStockMovement(model.StockMovement{
+			ProductID: product.ID,
+			Type:      model.StockMovementSale,
+			Delta:     -delta,
+			Balance:   newStock,
+			Reference: transactionUUID,
+		})
+	}
+
+	totalAmount := plan.TotalAmount
+	transactionDetails := plan.Details
+
+	currentTime := time.Now()
+	r.store.lastTransactionID++
+	record := &transactionRecord{
+		ID:          r.store.lastTransactionID,
+		StoreID:     storeID,
+		UUID:        transactionUUID,
+		TotalAmount: totalAmount,
+		PurchasedAt: currentTime,
+	}
+
+	for i, detail := range transactionDetails {
+		r.store.lastDetailID++
+		transactionDetails[i].ID = r.store.lastDetailID
+		transactionDetails[i].TransactionID = record.ID
+
+		record.Details = append(record.Details, detailRecord{
+			ID:        r.store.lastDetailID,
+			ProductID: detail.ProductID,
+			Name:      detail.ProductName,
+			Price:     detail.Price,
+			Quantity:  detail.Quantity,
+			SubTotal:  detail.SubTotal,
+		})
+	}
+	r.store.transactions = append(r.store.transactions, record)
+
+	transaction := model.Transaction{
+		ID:          record.ID,
+		UUID:        record.UUID,
+		TotalAmount: totalAmount,
+		PurchasedAt: currentTime,
+		Details:     transactionDetails,
+	}
+
+	return &transaction, nil
+}
+
+func (r *checkoutRepository) GetAllTransaction(ctx context.Context, storeID int64, filter repository.TransactionFilter) ([]model.Transaction, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	transactions := make([]model.Transaction, 0)
+	for i := len(r.store.transactions) - 1; i >= 0; i-- {
+		t := r.store.transactions[i]
+
+		if t.StoreID != storeID {
+			continue
+		}
+		if filter.From != nil && t.PurchasedAt.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !t.PurchasedAt.Before(*filter.To) {
+			continue
+		}
+		if filter.MinAmount != nil && t.TotalAmount < *filter.MinAmount {
+			continue
+		}
+		if filter.MaxAmount != nil && t.TotalAmount > *filter.MaxAmount {
+			continue
+		}
+		if filter.ProductUUID != "" && !r.store.hasProduct(t, filter.ProductUUID) {
+			continue
+		}
+		if filter.BeforeID > 0 && t.ID >= filter.BeforeID {
+			continue
+		}
+
+		transactions = append(transactions, r.store.toTransactionModel(t))
+		if filter.Limit > 0 && len(transactions) == filter.Limit {
+			break
+		}
+	}
+
+	return transactions, nil
+}
+
+func (r *checkoutRepository) GetTransactionByUUID(ctx context.Context, storeID int64, uuid string) (*model.Transaction, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, t := range r.store.transactions {
+		if t.StoreID == storeID && t.UUID == uuid {
+			transaction := r.store.toTransactionModel(t)
+			return &transaction, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// hasProduct reports whether the transaction contains the product with the given UUID.
+// The caller must hold the lock.
+func (s *Store) hasProduct(t *transactionRecord, productUUID string) bool {
+	for _, d := range t.Details {
+		p := s.findProductByID(d.ProductID)
+		if p != nil && p.UUID == productUUID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// toTransactionModel converts a stored transaction record to model.Transaction.
+// The caller must hold the lock.
+func (s *Store) toTransactionModel(t *transactionRecord) model.Transaction {
+	transaction := model.Transaction{
+		ID:          t.ID,
+		UUID:        t.UUID,
+		TotalAmount: t.TotalAmount,
+		PurchasedAt: t.PurchasedAt,
+		Details:     make([]model.TransactionDetail, 0, len(t.Details)),
+	}
+
+	refunded := make(map[int64]int64)
+	transaction.Refunds = make([]model.Refund, 0, len(t.Refunds))
+	for _, rf := range t.Refunds {
+		refund := rf
+		refund.Details = make([]model.RefundDetail, 0, len(rf.Details))
+		for _, d := range rf.Details {
+			refunded[d.TransactionDetailID] += d.Quantity
+			if p := s.findProductByID(d.ProductID); p != nil {
+				d.ProductUUID = p.UUID
+			}
+			refund.Details = append(refund.Details, d)
+		}
+		transaction.Refunds = append(transaction.Refunds, refund)
+	}
+
+	for _, d := range t.Details {
+		detail := model.TransactionDetail{
+			ID:               d.ID,
+			TransactionID:    t.ID,
+			ProductID:        d.ProductID,
+			ProductName:      d.Name,
+			Price:            d.Price,
+			Quantity:         d.Quantity,
+			SubTotal:         d.SubTotal,
+			RefundedQuantity: refunded[d.ID],
+		}
+		if p := s.findProductByID(d.ProductID); p != nil {
+			detail.ProductUUID = p.UUID
+		}
+		transaction.Details = append(transaction.Details, detail)
+	}
+
+	return transaction
+}
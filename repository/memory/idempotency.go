@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+)
+
+type idempotencyRepository struct {
+	store *Store
+}
+
+func NewIdempotencyRepository(store *Store) repository.IdempotencyRepository {
+	return &idempotencyRepository{store: store}
+}
+
+func (r *idempotencyRepository) ReserveIdempotencyKey(ctx context.Context, rec model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	id := rec.Scope + " " + rec.Key
+	if existing, ok := r.store.idempotencyKeys[id]; ok {
+		return &existing, nil
+	}
+
+	r.store.idempotencyKeys[id] = rec
+	return nil, nil
+}
+
+func (r *idempotencyRepository) CompleteIdempotencyKey(ctx context.Context, rec model.IdempotencyRecord) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	id := rec.Scope + " " + rec.Key
+	if existing, ok := r.store.idempotencyKeys[id]; ok {
+		existing.StatusCode = rec.StatusCode
+		existing.ContentType = rec.ContentType
+		existing.ResponseBody = rec.ResponseBody
+		existing.CompletedAt = rec.CompletedAt
+		r.store.idempotencyKeys[id] = existing
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteIdempotencyKey(ctx context.Context, scope, key string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.idempotencyKeys, scope+" "+key)
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"cmp"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"slices"
+)
+
+// paginate orders rows by their sort key and ID, like the SQL listings do, then keeps
+// the rows after the cursor, or skips offset rows when there is no cursor, and returns
+// at most limit rows.
+func paginate[T any](rows []T, key func(T) interface{}, id func(T) int64, desc bool, after *repository.ListCursor, offset, limit int) []T {
+	compare := func(aKey interface{}, aID int64, bKey interface{}, bID int64) int {
+		c := compareSortKeys(aKey, bKey)
+		if c == 0 {
+			c = cmp.Compare(aID, bID)
+		}
+		if desc {
+			c = -c
+		}
+		return c
+	}
+
+	slices.SortFunc(rows, func(a, b T) int {
+		return compare(key(a), id(a), key(b), id(b))
+	})
+
+	if after != nil {
+		start := len(rows)
+		for i, row := range rows {
+			if compare(key(row), id(row), after.Value, after.ID) > 0 {
+				start = i
+				break
+			}
+		}
+		rows = rows[start:]
+	} else if offset > 0 {
+		rows = rows[min(offset, len(rows)):]
+	}
+
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	return rows
+}
+
+// compareSortKeys compares two sort keys of the same type. Keys of other types,
+// including nil for SortByCreated, compare as equal.
+func compareSortKeys(a, b interface{}) int {
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return cmp.Compare(a, b)
+		}
+	case model.Money:
+		if b, ok := b.(model.Money); ok {
+			return cmp.Compare(a, b)
+		}
+	case int64:
+		if b, ok := b.(int64); ok {
+			return cmp.Compare(a, b)
+		}
+	}
+
+	return 0
+}
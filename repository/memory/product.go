@@ -0,0 +1,357 @@
+package memory
+
+import (
+	"context"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"slices"
+	"strings"
+	"time"
+)
+
+type productRepository struct {
+	store *Store
+}
+
+func NewProductRepository(store *Store) repository.ProductRepository {
+	return &productRepository{store: store}
+}
+
+func (r *productRepository) GetAllProduct(ctx context.Context, storeID int64, filter repository.ProductFilter) ([]model.Product, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	products := paginate(r.store.filterProducts(storeID, filter),
+		func(p model.Product) interface{} { return repository.ProductSortKey(p, filter.Sort) },
+		func(p model.Product) int64 { return p.ID },
+		filter.Desc, filter.After, filter.Offset, filter.Limit)
+
+	return products, nil
+}
+
+func (r *productRepository) CountProduct(ctx context.Context, storeID int64, filter repository.ProductFilter) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return int64(len(r.store.filterProducts(storeID, filter))), nil
+}
+
+// filterProducts returns the live products of the store matching filter, unsorted.
+// The caller must hold the lock.
+func (s *Store) filterProducts(storeID int64, filter repository.ProductFilter) []model.Product {
+	keyword := strings.ToLower(filter.Keyword)
+
+	products := make([]model.Product, 0)
+	for _, p := range s.products {
+		if p.StoreID != storeID || p.DeletedAt != nil {
+			continue
+		}
+		if len(keyword) > 0 && !strings.Contains(strings.ToLower(p.Name), keyword) {
+			continue
+		}
+		if filter.CategoryUUID != "" {
+			if p.CategoryID == nil {
+				continue
+			}
+			c := s.findCategoryByID(*p.CategoryID)
+			if c == nil || c.UUID != filter.CategoryUUID {
+				continue
+			}
+		}
+		if filter.SKU != "" && p.SKU != filter.SKU {
+			continue
+		}
+		if filter.Barcode != "" && (p.Barcode == nil || *p.Barcode != filter.Barcode) {
+			continue
+		}
+		if filter.MinPrice != nil && (p.Price == nil || *p.Price < *filter.MinPrice) {
+			continue
+		}
+		if filter.MaxPrice != nil && (p.Price == nil || *p.Price > *filter.MaxPrice) {
+			continue
+		}
+
+		switch filter.Stock {
+		case repository.StockFilterInStock:
+			if p.Stock == nil || *p.Stock <= 0 {
+				continue
+			}
+		case repository.StockFilterOutOfStock:
+			if p.Stock == nil || *p.Stock > 0 {
+				continue
+			}
+		case repository.StockFilterUnlimited:
+			if p.Stock != nil {
+				continue
+			}
+		}
+
+		products = append(products, s.toProductModel(p))
+	}
+
+	return products
+}
+
+func (r *productRepository) GetProductByUUID(ctx context.Context, storeID int64, uuid string) (*model.Product, error) {
+	isValidUUID := helper.IsValidUUID(uuid)
+	if !isValidUUID {
+		return nil, nil
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, p := range r.store.products {
+		if p.StoreID == storeID && p.UUID == uuid && p.DeletedAt == nil {
+			product := r.store.toProductModel(p)
+			return &product, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *productRepository) CreateProduct(ctx context.Context, storeID int64, p model.Product) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var categoryID *int64
+	if p.Category != nil && p.Category.ID != 0 {
+		categoryID = &p.Category.ID
+	}
+
+	err := r.store.checkProductCodes(storeID, p)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	r.store.lastProductID++
+	r.store.products = append(r.store.products, &productRecord{
+		ID:         r.store.lastProductID,
+		StoreID:    storeID,
+		UUID:       p.UUID,
+		SKU:        p.SKU,
+		Barcode:    cloneString(p.Barcode),
+		Name:       p.Name,
+		Stock:      cloneInt64(p.Stock),
+		Price:      cloneMoney(p.Price),
+		CategoryID: cloneInt64(categoryID),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+
+	// The opening stock is the first ledger entry of a tracked product.
+	if p.Stock != nil {
+		r.store.recordStockMovement(model.StockMovement{
+			ProductID: r.store.lastProductID,
+			Type:      model.StockMovementReceiving,
+			Delta:     *p.Stock,
+			Balance:   *p.Stock,
+			Reference: "initial stock",
+		})
+	}
+
+	return nil
+}
+
+// UpdateProduct updates the product details. Stock only changes through checkouts,
+// refunds and stock adjustments so the ledger stays complete.
+func (r *productRepository) UpdateProduct(ctx context.Context, storeID int64, p model.Product, entry model.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var categoryID *int64
+	if p.Category != nil {
+		categoryID = &p.Category.ID
+	}
+
+	err := r.store.checkProductCodes(storeID, p)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range r.store.products {
+		if record.StoreID == storeID && record.UUID == p.UUID {
+			record.SKU = p.SKU
+			record.Barcode = cloneString(p.Barcode)
+			record.Name = p.Name
+			record.Price = cloneMoney(p.Price)
+			record.CategoryID = cloneInt64(categoryID)
+			record.UpdatedAt = time.Now()
+		}
+	}
+	r.store.recordAuditEntry(storeID, entry)
+
+	return nil
+}
+
+func (r *productRepository) DeleteProduct(ctx context.Context, storeID int64, uuid string, entry model.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now().UTC()
+	for _, record := range r.store.products {
+		if record.StoreID == storeID && record.UUID == uuid && record.DeletedAt == nil {
+			record.DeletedAt = &now
+			r.store.recordAuditEntry(storeID, entry)
+			return nil
+		}
+	}
+
+	return repository.ErrProductNotFound
+}
+
+func (r *productRepository) GetDeletedProducts(ctx context.Context, storeID int64, filter repository.TrashFilter) ([]model.Product, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	products := make([]model.Product, 0)
+	for i := len(r.store.products) - 1; i >= 0; i-- {
+		p := r.store.products[i]
+		if p.StoreID != storeID || !inTrash(p.DeletedAt, filter) {
+			continue
+		}
+		if filter.BeforeID > 0 && p.ID >= filter.BeforeID {
+			continue
+		}
+
+		products = append(products, r.store.toProductModel(p))
+		if filter.Limit > 0 && len(products) == filter.Limit {
+			break
+		}
+	}
+
+	return products, nil
+}
+
+func (r *productRepository) GetDeletedProductByUUID(ctx context.Context, storeID int64, uuid string) (*model.Product, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, p := range r.store.products {
+		if p.StoreID == storeID && p.UUID == uuid && p.DeletedAt != nil {
+			product := r.store.toProductModel(p)
+			return &product, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *productRepository) RestoreProduct(ctx context.Context, storeID int64, uuid string, entry model.AuditEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, record := range r.store.products {
+		if record.StoreID == storeID && record.UUID == uuid && record.DeletedAt != nil {
+			record.DeletedAt = nil
+			record.UpdatedAt = time.Now()
+			r.store.recordAuditEntry(storeID, entry)
+			return nil
+		}
+	}
+
+	return repository.ErrProductNotFound
+}
+
+func (r *productRepository) PurgeProducts(ctx context.Context, storeID int64, entries []model.AuditEntry) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var purged int64
+	for _, entry := range entries {
+		i := slices.IndexFunc(r.store.products, func(p *productRecord) bool {
+			return p.StoreID == storeID && p.UUID == entry.EntityID && p.DeletedAt != nil
+		})
+		if i < 0 || r.store.productSold(r.store.products[i].ID) {
+			continue
+		}
+
+		id := r.store.products[i].ID
+		r.store.stockMovements = slices.DeleteFunc(r.store.stockMovements, func(m model.StockMovement) bool {
+			return m.ProductID == id
+		})
+		r.store.products = slices.Delete(r.store.products, i, i+1)
+		r.store.recordAuditEntry(storeID, entry)
+		purged++
+	}
+
+	return purged, nil
+}
+
+// productSold reports whether a product appears on any transaction. The caller must
+// hold the lock.
+func (s *Store) productSold(id int64) bool {
+	for _, t := range s.transactions {
+		for _, d := range t.Details {
+			if d.ProductID == id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (r *productRepository) NextSKUSequence(ctx context.Context, prefix string) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	last, ok := r.store.skuSequences[prefix]
+	if !ok {
+		for _, p := range r.store.products {
+			if strings.HasPrefix(p.SKU, prefix) {
+				last++
+			}
+		}
+	}
+
+	r.store.skuSequences[prefix] = last + 1
+	return last + 1, nil
+}
+
+// checkProductCodes returns repository.ErrDuplicateSKU or repository.ErrDuplicateBarcode
+// when another product, deleted ones included, already uses the SKU of p in any store
+// or its barcode in the store. The caller must hold the lock.
+func (s *Store) checkProductCodes(storeID int64, p model.Product) error {
+	for _, record := range s.products {
+		if record.UUID == p.UUID {
+			continue
+		}
+		if record.SKU == p.SKU {
+			return repository.ErrDuplicateSKU
+		}
+		if record.StoreID == storeID && p.Barcode != nil && record.Barcode != nil && *record.Barcode == *p.Barcode {
+			return repository.ErrDuplicateBarcode
+		}
+	}
+
+	return nil
+}
+
+// toProductModel converts a stored product record to model.Product, joining its
+// category when the category still exists. The caller must hold the lock.
+func (s *Store) toProductModel(p *productRecord) model.Product {
+	product := model.Product{
+		ID:        p.ID,
+		UUID:      p.UUID,
+		SKU:       p.SKU,
+		Barcode:   cloneString(p.Barcode),
+		Name:      p.Name,
+		Stock:     cloneInt64(p.Stock),
+		Price:     cloneMoney(p.Price),
+		DeletedAt: cloneTime(p.DeletedAt),
+	}
+
+	if p.CategoryID != nil {
+		c := s.findCategoryByID(*p.CategoryID)
+		if c != nil && c.DeletedAt == nil {
+			category := toCategoryModel(c)
+			product.Category = &category
+		}
+	}
+
+	return product
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"fendi/modul-03-task/transport"
+	"time"
+)
+
+func (r *checkoutRepository) CreateRefund(ctx context.Context, storeID int64, transactionUUID string, refundType string, req transport.RefundRequest) (*model.Refund, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var record *transactionRecord
+	for _, t := range r.store.transactions {
+		if t.StoreID == storeID && t.UUID == transactionUUID {
+			record = t
+			break
+		}
+	}
+	if record == nil {
+		return nil, repository.ErrTransactionNotFound
+	}
+
+	transaction := r.store.toTransactionModel(record)
+	refundDetails, totalAmount, err := repository.PlanRefund(transaction.Details, refundType, req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.lastRefundID++
+	refund := model.Refund{
+		ID:            r.store.lastRefundID,
+		UUID:          helper.GenerateUUID(),
+		TransactionID: record.ID,
+		Type:          refundType,
+		Reason:        req.Reason,
+		TotalAmount:   totalAmount,
+		CreatedAt:     time.Now(),
+	}
+
+	for i, d := range refundDetails {
+		r.store.lastRefundLineID++
+		refundDetails[i].ID = r.store.lastRefundLineID
+		refundDetails[i].RefundID = refund.ID
+
+		product := r.store.findProductByID(d.ProductID)
+		if product != nil && product.Stock != nil {
+			newStock := *product.Stock + d.Quantity
+			product.Stock = &newStock
+
+			r.store.recordStockMovement(model.StockMovement{
+				ProductID: product.ID,
+				Type:      model.StockMovementRefund,
+				Delta:     d.Quantity,
+				Balance:   newStock,
+				Reference: refund.UUID,
+			})
+		}
+	}
+
+	refund.Details = refundDetails
+	stored := refund
+	stored.Details = append([]model.RefundDetail(nil), refundDetails...)
+	record.Refunds = append(record.Refunds, stored)
+
+	return &refund, nil
+}
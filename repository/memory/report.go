@@ -0,0 +1,257 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"slices"
+	"time"
+)
+
+type reportRepository struct {
+	store *Store
+}
+
+func NewReportRepository(store *Store) repository.ReportRepository {
+	return &reportRepository{store: store}
+}
+
+// inPeriod reports whether t falls in the half-open period of filter.
+func inPeriod(t time.Time, filter repository.ReportFilter) bool {
+	return !t.Before(filter.From) && t.Before(filter.To)
+}
+
+// productSales accumulates the figures of one product during a report.
+type productSales struct {
+	productID int64
+	quantity  int64
+	revenue   model.Money
+}
+
+func (r *reportRepository) FetchReport(ctx context.Context, storeID int64, filter repository.ReportFilter) (model.ReportData, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var report model.ReportData
+	sales := make(map[int64]*productSales)
+	add := func(productID, quantity int64, revenue model.Money) {
+		s, ok := sales[productID]
+		if !ok {
+			s = &productSales{productID: productID}
+			sales[productID] = s
+		}
+		s.quantity += quantity
+		s.revenue += revenue
+		report.ItemsSold += quantity
+	}
+
+	for _, t := range r.store.transactions {
+		if t.StoreID != storeID {
+			continue
+		}
+		// Refunds count in the period they were made, not when the sale happened.
+		for _, rf := range t.Refunds {
+			if !inPeriod(rf.CreatedAt, filter) {
+				continue
+			}
+			report.TotalRevenue -= rf.TotalAmount
+			for _, d := range rf.Details {
+				add(d.ProductID, -d.Quantity, -d.SubTotal)
+			}
+		}
+
+		if !inPeriod(t.PurchasedAt, filter) {
+			continue
+		}
+
+		report.TotalTransaction++
+		report.GrossSales += t.TotalAmount
+		report.TotalRevenue += t.TotalAmount
+
+		for _, d := range t.Details {
+			add(d.ProductID, d.Quantity, d.SubTotal)
+		}
+	}
+
+	all := make([]productSales, 0, len(sales))
+	for _, s := range sales {
+		all = append(all, *s)
+	}
+	report.TopByQuantity = r.topProducts(all, filter.Top, func(s productSales) (int64, int64) {
+		return s.quantity, int64(s.revenue)
+	})
+	report.TopByRevenue = r.topProducts(all, filter.Top, func(s productSales) (int64, int64) {
+		return int64(s.revenue), s.quantity
+	})
+
+	return report, nil
+}
+
+// topProducts ranks sales by the first figure of rank, ties broken by the second and
+// the oldest product first, like the SQL report. Products whose first figure is not
+// positive are left out.
+func (r *reportRepository) topProducts(sales []productSales, top int, rank func(productSales) (int64, int64)) []model.ProductSales {
+	ranked := slices.DeleteFunc(slices.Clone(sales), func(s productSales) bool {
+		by, _ := rank(s)
+		return by <= 0
+	})
+	slices.SortFunc(ranked, func(a, b productSales) int {
+		aBy, aThen := rank(a)
+		bBy, bThen := rank(b)
+		return cmp.Or(cmp.Compare(bBy, aBy), cmp.Compare(bThen, aThen), cmp.Compare(a.productID, b.productID))
+	})
+	if len(ranked) > top {
+		ranked = ranked[:top]
+	}
+
+	products := make([]model.ProductSales, 0, len(ranked))
+	for _, s := range ranked {
+		p := model.ProductSales{Quantity: s.quantity, Revenue: s.revenue}
+		if product := r.store.findProductByID(s.productID); product != nil {
+			p.ProductID = product.UUID
+			p.ProductName = product.Name
+		}
+		products = append(products, p)
+	}
+
+	return products
+}
+
+func (r *reportRepository) FetchSalesEntries(ctx context.Context, storeID int64, filter repository.ReportFilter) ([]model.SalesEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var entries []model.SalesEntry
+	for _, t := range r.store.transactions {
+		if t.StoreID != storeID {
+			continue
+		}
+		for _, rf := range t.Refunds {
+			if !inPeriod(rf.CreatedAt, filter) {
+				continue
+			}
+			e := model.SalesEntry{At: rf.CreatedAt, Revenue: -rf.TotalAmount}
+			for _, d := range rf.Details {
+				e.ItemsSold -= d.Quantity
+			}
+			entries = append(entries, e)
+		}
+
+		if !inPeriod(t.PurchasedAt, filter) {
+			continue
+		}
+		e := model.SalesEntry{At: t.PurchasedAt, Revenue: t.TotalAmount, Transactions: 1}
+		for _, d := range t.Details {
+			e.ItemsSold += d.Quantity
+		}
+		entries = append(entries, e)
+	}
+
+	slices.SortStableFunc(entries, func(a, b model.SalesEntry) int {
+		return a.At.Compare(b.At)
+	})
+
+	return entries, nil
+}
+
+func (r *reportRepository) FetchCategorySales(ctx context.Context, storeID int64, filter repository.ReportFilter) ([]model.CategorySales, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	// Sales are keyed by category ID, 0 for products without a category.
+	sales := make(map[int64]*model.CategorySales)
+	transactions := make(map[int64]map[int64]bool)
+	add := func(productID, quantity int64, revenue model.Money, transactionID int64) {
+		var categoryID int64
+		if p := r.store.findProductByID(productID); p != nil && p.CategoryID != nil {
+			categoryID = *p.CategoryID
+		}
+
+		c, ok := sales[categoryID]
+		if !ok {
+			c = &model.CategorySales{}
+			if category := r.store.findCategoryByID(categoryID); category != nil {
+				uuid := category.UUID
+				c.CategoryID = &uuid
+				c.CategoryName = category.Name
+				c.Deleted = category.DeletedAt != nil
+			}
+			sales[categoryID] = c
+			transactions[categoryID] = make(map[int64]bool)
+		}
+		c.Quantity += quantity
+		c.Revenue += revenue
+		if transactionID != 0 && !transactions[categoryID][transactionID] {
+			transactions[categoryID][transactionID] = true
+			c.Transactions++
+		}
+	}
+
+	for _, t := range r.store.transactions {
+		if t.StoreID != storeID {
+			continue
+		}
+		for _, rf := range t.Refunds {
+			if !inPeriod(rf.CreatedAt, filter) {
+				continue
+			}
+			for _, d := range rf.Details {
+				add(d.ProductID, -d.Quantity, -d.SubTotal, 0)
+			}
+		}
+
+		if !inPeriod(t.PurchasedAt, filter) {
+			continue
+		}
+		for _, d := range t.Details {
+			add(d.ProductID, d.Quantity, d.SubTotal, t.ID)
+		}
+	}
+
+	categories := make([]model.CategorySales, 0, len(sales))
+	for _, c := range sales {
+		categories = append(categories, *c)
+	}
+	slices.SortFunc(categories, func(a, b model.CategorySales) int {
+		return cmp.Or(cmp.Compare(b.Revenue, a.Revenue), cmp.Compare(a.CategoryName, b.CategoryName))
+	})
+
+	return categories, nil
+}
+
+func (r *reportRepository) FetchStoreSales(ctx context.Context, filter repository.ReportFilter) ([]model.StoreSales, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	stores := make([]model.StoreSales, 0, len(r.store.stores))
+	for _, st := range r.store.stores {
+		sales := model.StoreSales{Store: *st}
+		for _, t := range r.store.transactions {
+			if t.StoreID != st.ID {
+				continue
+			}
+			for _, rf := range t.Refunds {
+				if !inPeriod(rf.CreatedAt, filter) {
+					continue
+				}
+				sales.Refunded += rf.TotalAmount
+				for _, d := range rf.Details {
+					sales.ItemsSold -= d.Quantity
+				}
+			}
+
+			if !inPeriod(t.PurchasedAt, filter) {
+				continue
+			}
+			sales.Transactions++
+			sales.GrossSales += t.TotalAmount
+			for _, d := range t.Details {
+				sales.ItemsSold += d.Quantity
+			}
+		}
+		stores = append(stores, sales)
+	}
+
+	return stores, nil
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"fmt"
+	"time"
+)
+
+type stockRepository struct {
+	store *Store
+}
+
+func NewStockRepository(store *Store) repository.StockRepository {
+	return &stockRepository{store: store}
+}
+
+func (r *stockRepository) AdjustStock(ctx context.Context, storeID int64, productUUID string, m model.StockMovement) (*model.StockMovement, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var product *productRecord
+	for _, p := range r.store.products {
+		if p.StoreID == storeID && p.UUID == productUUID && p.DeletedAt == nil {
+			product = p
+			break
+		}
+	}
+	if product == nil {
+		return nil, repository.ErrProductNotFound
+	}
+	if product.Stock == nil {
+		return nil, repository.ErrStockNotTracked
+	}
+
+	newStock := *product.Stock + m.Delta
+	if newStock > model.MaxStock {
+		return nil, repository.NewValidationError(repository.ErrInvalidStockAdjustment, "delta", fmt.Sprintf("would take stock above %d", model.MaxStock))
+	}
+	if newStock < 0 {
+		return nil, repository.ErrNegativeStock
+	}
+	product.Stock = &newStock
+	product.UpdatedAt = time.Now()
+
+	m.ProductID = product.ID
+	m.Balance = newStock
+	m.Note = cloneString(m.Note)
+	stored := r.store.recordStockMovement(m)
+	stored.ProductUUID = product.UUID
+
+	return &stored, nil
+}
+
+func (r *stockRepository) GetStockMovements(ctx context.Context, storeID int64, productUUID string, beforeID int64, limit int) ([]model.StockMovement, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	movements := make([]model.StockMovement, 0)
+	for i := len(r.store.stockMovements) - 1; i >= 0; i-- {
+		m := r.store.stockMovements[i]
+		if beforeID > 0 && m.ID >= beforeID {
+			continue
+		}
+
+		product := r.store.findProductByID(m.ProductID)
+		if product == nil || product.StoreID != storeID || product.UUID != productUUID {
+			continue
+		}
+
+		m.ProductUUID = product.UUID
+		m.Note = cloneString(m.Note)
+		movements = append(movements, m)
+		if limit > 0 && len(movements) == limit {
+			break
+		}
+	}
+
+	return movements, nil
+}
@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"sync"
+	"time"
+)
+
+// Store holds the in-memory tables shared by every memory repository.
+// A single mutex guards all tables so multi-table operations such as
+// checkout behave like a database transaction.
+type Store struct {
+	mu sync.RWMutex
+
+	categories   []*categoryRecord
+	products     []*productRecord
+	transactions []*transactionRecord
+
+	stockMovements []model.StockMovement
+	auditEntries   []auditRecord
+
+	idempotencyKeys map[string]model.IdempotencyRecord
+	skuSequences    map[string]int64
+
+	stores  []*model.Store
+	users   []*userRecord
+	apiKeys []*model.APIKey
+
+	lastCategoryID    int64
+	lastProductID     int64
+	lastTransactionID int64
+	lastDetailID      int64
+	lastRefundID      int64
+	lastRefundLineID  int64
+	lastMovementID    int64
+	lastAuditID       int64
+	lastStoreID       int64
+	lastUserID        int64
+	lastAPIKeyID      int64
+}
+
+// NewStore creates an in-memory store holding only the default shop, like a freshly
+// migrated database.
+func NewStore() *Store {
+	return &Store{
+		idempotencyKeys: make(map[string]model.IdempotencyRecord),
+		skuSequences:    make(map[string]int64),
+		stores:          []*model.Store{{ID: 1, UUID: model.DefaultStoreUUID, Name: "Main store", CreatedAt: time.Now().UTC()}},
+		lastStoreID:     1,
+	}
+}
+
+type categoryRecord struct {
+	ID          int64
+	StoreID     int64
+	UUID        string
+	Name        string
+	Description *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   *time.Time
+}
+
+type productRecord struct {
+	ID         int64
+	StoreID    int64
+	UUID       string
+	SKU        string
+	Barcode    *string
+	Name       string
+	Stock      *int64
+	Price      *model.Money
+	CategoryID *int64
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  *time.Time
+}
+
+type auditRecord struct {
+	StoreID int64
+	model.AuditEntry
+}
+
+type transactionRecord struct {
+	ID          int64
+	StoreID     int64
+	UUID        string
+	TotalAmount model.Money
+	PurchasedAt time.Time
+	Details     []detailRecord
+	Refunds     []model.Refund
+}
+
+type detailRecord struct {
+	ID        int64
+	ProductID int64
+	Name      string
+	Price     model.Money
+	Quantity  int64
+	SubTotal  model.Money
+}
+
+// findCategoryByID returns the category with the given ID, including soft-deleted ones.
+// The caller must hold the lock.
+func (s *Store) findCategoryByID(id int64) *categoryRecord {
+	for _, c := range s.categories {
+		if c.ID == id {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// findStoreByID returns the store with the given ID. The caller must hold the lock.
+func (s *Store) findStoreByID(id int64) *model.Store {
+	for _, st := range s.stores {
+		if st.ID == id {
+			return st
+		}
+	}
+
+	return nil
+}
+
+// findProductByID returns the product with the given ID, including soft-deleted ones.
+// The caller must hold the lock.
+func (s *Store) findProductByID(id int64) *productRecord {
+	for _, p := range s.products {
+		if p.ID == id {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// recordStockMovement appends m to the stock ledger and returns the stored entry.
+// The caller must hold the lock.
+func (s *Store) recordStockMovement(m model.StockMovement) model.StockMovement {
+	s.lastMovementID++
+	m.ID = s.lastMovementID
+	m.CreatedAt = time.Now()
+	s.stockMovements = append(s.stockMovements, m)
+
+	return m
+}
+
+// recordAuditEntry appends e to the audit trail of the store. The caller must hold
+// the lock.
+func (s *Store) recordAuditEntry(storeID int64, e model.AuditEntry) {
+	s.lastAuditID++
+	e.ID = s.lastAuditID
+	e.CreatedAt = time.Now().UTC()
+	s.auditEntries = append(s.auditEntries, auditRecord{StoreID: storeID, AuditEntry: e})
+}
+
+// cloneInt64 copies a nullable int64 so callers cannot mutate stored state.
+func cloneInt64(v *int64) *int64 {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+// cloneMoney copies a nullable amount so callers cannot mutate stored state.
+func cloneMoney(v *model.Money) *model.Money {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+// cloneTime copies a nullable time so callers cannot mutate stored state.
+func cloneTime(v *time.Time) *time.Time {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+// inTrash reports whether a record deleted at deletedAt is in the trash and passes
+// the deletion cutoff of filter.
+func inTrash(deletedAt *time.Time, filter repository.TrashFilter) bool {
+	if deletedAt == nil {
+		return false
+	}
+
+	return filter.DeletedBefore == nil || deletedAt.Before(*filter.DeletedBefore)
+}
+
+// cloneString copies a nullable string so callers cannot mutate stored state.
+func cloneString(v *string) *string {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+)
+
+type storeRepository struct {
+	store *Store
+}
+
+func NewStoreRepository(store *Store) repository.StoreRepository {
+	return &storeRepository{store: store}
+}
+
+func (r *storeRepository) GetAllStore(ctx context.Context) ([]model.Store, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	stores := make([]model.Store, 0, len(r.store.stores))
+	for _, s := range r.store.stores {
+		stores = append(stores, *s)
+	}
+
+	return stores, nil
+}
+
+func (r *storeRepository) GetStoreByUUID(ctx context.Context, uuid string) (*model.Store, error) {
+	if !helper.IsValidUUID(uuid) {
+		return nil, nil
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, s := range r.store.stores {
+		if s.UUID == uuid {
+			store := *s
+			return &store, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *storeRepository) CreateStore(ctx context.Context, s model.Store) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.lastStoreID++
+	s.ID = r.store.lastStoreID
+	r.store.stores = append(r.store.stores, &s)
+
+	return nil
+}
+
+func (r *storeRepository) UpdateStore(ctx context.Context, s model.Store) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, record := range r.store.stores {
+		if record.UUID == s.UUID {
+			record.Name = s.Name
+			record.Address = s.Address
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package memory
+
+import (
+	"context"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"time"
+)
+
+// userRecord is a stored user. The embedded User keeps no Store; StoreID links it.
+type userRecord struct {
+	model.User
+	StoreID   *int64
+	DeletedAt *time.Time
+}
+
+type userRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) repository.UserRepository {
+	return &userRepository{store: store}
+}
+
+// findUser returns the first live user match accepts, or nil. The caller must hold
+// the lock.
+func (s *Store) findUser(match func(u *userRecord) bool) *userRecord {
+	for _, u := range s.users {
+		if u.DeletedAt == nil && match(u) {
+			return u
+		}
+	}
+
+	return nil
+}
+
+// toUserModel converts a stored user record to model.User, joining its store. The
+// caller must hold the lock.
+func (s *Store) toUserModel(u *userRecord) model.User {
+	user := u.User
+	if u.StoreID != nil {
+		if store := s.findStoreByID(*u.StoreID); store != nil {
+			st := *store
+			user.Store = &st
+		}
+	}
+
+	return user
+}
+
+// userStoreID returns the store ID of u, or nil.
+func userStoreID(u model.User) *int64 {
+	if u.Store == nil {
+		return nil
+	}
+	id := u.Store.ID
+	return &id
+}
+
+func (r *userRepository) GetAllUser(ctx context.Context) ([]model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := make([]model.User, 0)
+	for _, u := range r.store.users {
+		if u.DeletedAt == nil {
+			users = append(users, r.store.toUserModel(u))
+		}
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) GetUserByUUID(ctx context.Context, uuid string) (*model.User, error) {
+	if !helper.IsValidUUID(uuid) {
+		return nil, nil
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	u := r.store.findUser(func(u *userRecord) bool { return u.UUID == uuid })
+	if u == nil {
+		return nil, nil
+	}
+	user := r.store.toUserModel(u)
+
+	return &user, nil
+}
+
+func (r *userRepository) CountUserByRole(ctx context.Context, role string) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var count int64
+	for _, u := range r.store.users {
+		if u.DeletedAt == nil && u.Role == role {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, u model.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.lastUserID++
+	u.ID = r.store.lastUserID
+	storeID := userStoreID(u)
+	u.Store = nil
+	r.store.users = append(r.store.users, &userRecord{User: u, StoreID: storeID})
+
+	return nil
+}
+
+func (r *userRepository) UpdateUser(ctx context.Context, u model.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if record := r.store.findUser(func(rec *userRecord) bool { return rec.UUID == u.UUID }); record != nil {
+		record.Name = u.Name
+		record.Role = u.Role
+		record.StoreID = userStoreID(u)
+	}
+
+	return nil
+}
+
+func (r *userRepository) DeleteUser(ctx context.Context, uuid string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if record := r.store.findUser(func(rec *userRecord) bool { return rec.UUID == uuid }); record != nil {
+		now := time.Now().UTC()
+		record.DeletedAt = &now
+	}
+
+	return nil
+}
+
+func (r *userRepository) GetAPIKeysByUser(ctx context.Context, userID int64) ([]model.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	keys := make([]model.APIKey, 0)
+	for _, k := range r.store.apiKeys {
+		if k.UserID == userID {
+			keys = append(keys, *k)
+		}
+	}
+
+	return keys, nil
+}
+
+func (r *userRepository) GetAPIKeyByUUID(ctx context.Context, uuid string) (*model.APIKey, error) {
+	if !helper.IsValidUUID(uuid) {
+		return nil, nil
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, k := range r.store.apiKeys {
+		if k.UUID != uuid {
+			continue
+		}
+		if r.store.findUser(func(u *userRecord) bool { return u.ID == k.UserID }) == nil {
+			return nil, nil
+		}
+		key := *k
+		return &key, nil
+	}
+
+	return nil, nil
+}
+
+func (r *userRepository) CreateAPIKey(ctx context.Context, k model.APIKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.lastAPIKeyID++
+	k.ID = r.store.lastAPIKeyID
+	r.store.apiKeys = append(r.store.apiKeys, &k)
+
+	return nil
+}
+
+func (r *userRepository) RevokeAPIKey(ctx context.Context, uuid string, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, k := range r.store.apiKeys {
+		if k.UUID == uuid && k.RevokedAt == nil {
+			at := at.UTC()
+			k.RevokedAt = &at
+		}
+	}
+
+	return nil
+}
+
+func (r *userRepository) GetUserByAPIKeyHash(ctx context.Context, hash string) (*model.User, *model.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, k := range r.store.apiKeys {
+		if k.Hash != hash || k.RevokedAt != nil {
+			continue
+		}
+		u := r.store.findUser(func(u *userRecord) bool { return u.ID == k.UserID })
+		if u == nil {
+			return nil, nil, nil
+		}
+		user, key := r.store.toUserModel(u), *k
+		return &user, &key, nil
+	}
+
+	return nil, nil, nil
+}
+
+func (r *userRepository) TouchAPIKey(ctx context.Context, id int64, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, k := range r.store.apiKeys {
+		if k.ID == id {
+			at := at.UTC()
+			k.LastUsedAt = &at
+		}
+	}
+
+	return nil
+}
@@ -3,37 +3,51 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fendi/modul-03-task/database"
 	"fendi/modul-03-task/helper"
 	"fendi/modul-03-task/model"
 	"fmt"
-
-	"github.com/lib/pq"
+	"strings"
+	"time"
 )
 
-type ProductRepository struct {
-	db *sql.DB
+type productRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
 }
 
-func NewProductRepository(db *sql.DB) *ProductRepository {
-	return &ProductRepository{db: db}
+func NewProductRepository(db *sql.DB, dialect database.Dialect) ProductRepository {
+	return &productRepository{db: db, dialect: dialect}
 }
 
-func (r *ProductRepository) GetAllProduct(ctx context.Context, keyword string) ([]model.Product, error) {
+func (r *productRepository) GetAllProduct(ctx context.Context, storeID int64, filter ProductFilter) ([]model.Product, error) {
 	query :=
 		`SELECT 
-			p.id, p.uuid, p.name, p.stock, p.price,
+			p.id, p.uuid, COALESCE(p.sku, ''), p.barcode, p.name, p.stock, p.price,
 			c.id, c.uuid, c.name, c.description
 		FROM products p
 		LEFT JOIN categories c ON p.category_id = c.id AND c.deleted_at IS NULL
 		WHERE p.deleted_at IS NULL`
 
-	var args []interface{}
-	if len(keyword) > 0 {
-		query += " AND p.name ILIKE '%' || $1 || '%'"
-		args = append(args, keyword)
+	where, args := r.filterConditions(storeID, filter)
+	query += where
+
+	sortExpr := productSortExpr(filter.Sort)
+	if filter.After != nil {
+		var cond string
+		cond, args = keysetCondition(sortExpr, "p.id", filter.Desc, *filter.After, args)
+		query += " AND " + cond
 	}
 
-	query += " ORDER BY p.id ASC"
+	query += orderBy(sortExpr, "p.id", filter.Desc)
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 && filter.After == nil {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -53,7 +67,7 @@ func (r *ProductRepository) GetAllProduct(ctx context.Context, keyword string) (
 		var categoryDesc sql.NullString
 
 		err := rows.Scan(
-			&p.ID, &p.UUID, &p.Name, &p.Stock, &p.Price,
+			&p.ID, &p.UUID, &p.SKU, &p.Barcode, &p.Name, &p.Stock, &p.Price,
 			&categoryDBID, &categoryUUID, &categoryName, &categoryDesc,
 		)
 		if err != nil {
@@ -79,7 +93,77 @@ func (r *ProductRepository) GetAllProduct(ctx context.Context, keyword string) (
 	return products, nil
 }
 
-func (r *ProductRepository) GetProductByUUID(ctx context.Context, uuid string) (*model.Product, error) {
+func (r *productRepository) CountProduct(ctx context.Context, storeID int64, filter ProductFilter) (int64, error) {
+	where, args := r.filterConditions(storeID, filter)
+
+	var total int64
+	query := "SELECT COUNT(*) FROM products p WHERE p.deleted_at IS NULL" + where
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&total)
+	if err != nil {
+		fmt.Println("repository.product.CountProduct() Query Error: ", err.Error())
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// filterConditions builds the AND conditions shared by GetAllProduct and CountProduct.
+func (r *productRepository) filterConditions(storeID int64, filter ProductFilter) (string, []interface{}) {
+	where := " AND p.store_id = $1"
+	args := []interface{}{storeID}
+
+	if len(filter.Keyword) > 0 {
+		args = append(args, filter.Keyword)
+		where += " AND " + r.dialect.ILike("p.name", fmt.Sprintf("$%d", len(args)))
+	}
+	if filter.CategoryUUID != "" {
+		args = append(args, filter.CategoryUUID)
+		where += fmt.Sprintf(" AND p.category_id IN (SELECT id FROM categories WHERE uuid = $%d)", len(args))
+	}
+	if filter.SKU != "" {
+		args = append(args, filter.SKU)
+		where += fmt.Sprintf(" AND p.sku = $%d", len(args))
+	}
+	if filter.Barcode != "" {
+		args = append(args, filter.Barcode)
+		where += fmt.Sprintf(" AND p.barcode = $%d", len(args))
+	}
+	if filter.MinPrice != nil {
+		args = append(args, *filter.MinPrice)
+		where += fmt.Sprintf(" AND p.price >= $%d", len(args))
+	}
+	if filter.MaxPrice != nil {
+		args = append(args, *filter.MaxPrice)
+		where += fmt.Sprintf(" AND p.price <= $%d", len(args))
+	}
+
+	switch filter.Stock {
+	case StockFilterInStock:
+		where += " AND p.stock > 0"
+	case StockFilterOutOfStock:
+		where += " AND p.stock <= 0"
+	case StockFilterUnlimited:
+		where += " AND p.stock IS NULL"
+	}
+
+	return where, args
+}
+
+// productSortExpr returns the SQL expression matching ProductSortKey.
+func productSortExpr(sort string) string {
+	switch sort {
+	case SortByName:
+		return "p.name"
+	case SortByPrice:
+		return fmt.Sprintf("COALESCE(p.price, %s)", unpricedSortKey)
+	case SortByStock:
+		return fmt.Sprintf("COALESCE(p.stock, %d)", unlimitedSortKey)
+	default:
+		return ""
+	}
+}
+
+func (r *productRepository) GetProductByUUID(ctx context.Context, storeID int64, uuid string) (*model.Product, error) {
 	isValidUUID := helper.IsValidUUID(uuid)
 	if !isValidUUID {
 		return nil, nil
@@ -87,13 +171,13 @@ func (r *ProductRepository) GetProductByUUID(ctx context.Context, uuid string) (
 
 	query := `
 		SELECT 
-			p.id, p.uuid, p.name, p.stock, p.price,
+			p.id, p.uuid, COALESCE(p.sku, ''), p.barcode, p.name, p.stock, p.price,
 			c.id, c.uuid, c.name, c.description
 		FROM products p
 		LEFT JOIN categories c ON p.category_id = c.id AND c.deleted_at IS NULL
-		WHERE p.uuid = $1 AND p.deleted_at IS NULL
+		WHERE p.uuid = $1 AND p.store_id = $2 AND p.deleted_at IS NULL
 	`
-	row := r.db.QueryRowContext(ctx, query, uuid)
+	row := r.db.QueryRowContext(ctx, query, uuid, storeID)
 
 	var p model.Product
 	var categoryDBID sql.NullInt64
@@ -101,7 +185,7 @@ func (r *ProductRepository) GetProductByUUID(ctx context.Context, uuid string) (
 	var categoryDesc sql.NullString
 
 	err := row.Scan(
-		&p.ID, &p.UUID, &p.Name, &p.Stock, &p.Price,
+		&p.ID, &p.UUID, &p.SKU, &p.Barcode, &p.Name, &p.Stock, &p.Price,
 		&categoryDBID, &categoryUUID, &categoryName, &categoryDesc,
 	)
 	if err != nil {
@@ -127,99 +211,375 @@ func (r *ProductRepository) GetProductByUUID(ctx context.Context, uuid string) (
 	return &p, nil
 }
 
-func (r *ProductRepository) GetProductBySKUs(ctx context.Context, sku []string) ([]model.Product, error) {
-	query :=
-		`SELECT 
-			p.id, p.uuid, p.sku, p.name, p.stock, p.price,
-			c.id, c.uuid, c.name, c.description
-		FROM products p
-		LEFT JOIN categories c ON p.category_id = c.id AND c.deleted_at IS NULL
-		WHERE 
-			p.deleted_at IS NULL`
+func (r *productRepository) CreateProduct(ctx context.Context, storeID int64, p model.Product) error {
+	var categoryID *int64
+	if p.Category != nil {
+		categoryID = &p.Category.ID
+	}
 
-	var args []interface{}
-	if len(sku) > 0 {
-		query += " AND p.sku = ANY($1)"
-		args = append(args, pq.Array(sku))
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		fmt.Println("repository.product.CreateProduct() Begin Error: ", err.Error())
+		return err
 	}
+	defer tx.Rollback()
 
-	query += " ORDER BY p.id ASC"
+	err = checkProductCodes(ctx, tx, storeID, p)
+	if err != nil {
+		return err
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	query := "INSERT INTO products (uuid, sku, barcode, name, stock, price, category_id, store_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id"
+	err = tx.QueryRowContext(ctx, query, p.UUID, p.SKU, p.Barcode, p.Name, p.Stock, p.Price, categoryID, storeID).Scan(&p.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return []model.Product{}, nil
+		fmt.Println("repository.product.CreateProduct() Exec Error: ", err.Error())
+		return r.uniqueViolation(err)
+	}
+
+	// The opening stock is the first ledger entry of a tracked product.
+	if p.Stock != nil {
+		err = recordStockMovement(ctx, tx, &model.StockMovement{
+			ProductID: p.ID,
+			Type:      model.StockMovementReceiving,
+			Delta:     *p.Stock,
+			Balance:   *p.Stock,
+			Reference: "initial stock",
+		})
+		if err != nil {
+			return err
 		}
-		fmt.Println("repository.product.GetProductBySKUs() Query Error: ", err.Error())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		fmt.Println("repository.product.CreateProduct() Commit Error: ", err.Error())
+	}
+
+	return err
+}
+
+// UpdateProduct updates the product details. Stock is not written here; it only
+// changes through checkouts, refunds and stock adjustments so the ledger stays complete.
+func (r *productRepository) UpdateProduct(ctx context.Context, storeID int64, p model.Product, entry model.AuditEntry) error {
+	var categoryID *int64
+	if p.Category != nil {
+		categoryID = &p.Category.ID
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		fmt.Println("repository.product.UpdateProduct() Begin Error: ", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	err = checkProductCodes(ctx, tx, storeID, p)
+	if err != nil {
+		return err
+	}
+
+	query := "UPDATE products SET sku = $1, barcode = $2, name = $3, price = $4, category_id = $5, updated_at = " + r.dialect.Now() + " WHERE uuid = $6 AND store_id = $7"
+	_, err = tx.ExecContext(ctx, query, p.SKU, p.Barcode, p.Name, p.Price, categoryID, p.UUID, storeID)
+	if err != nil {
+		fmt.Println("repository.product.UpdateProduct() Exec Error: ", err.Error())
+		return r.uniqueViolation(err)
+	}
+
+	err = recordAuditEntry(ctx, tx, storeID, &entry)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		fmt.Println("repository.product.UpdateProduct() Commit Error: ", err.Error())
+	}
+
+	return err
+}
+
+func (r *productRepository) NextSKUSequence(ctx context.Context, prefix string) (int64, error) {
+	var next int64
+	query := `INSERT INTO sku_sequences (prefix, last_value)
+		VALUES ($1, (SELECT COUNT(*) FROM products WHERE substr(sku, 1, $2) = $1) + 1)
+		ON CONFLICT (prefix) DO UPDATE SET last_value = sku_sequences.last_value + 1
+		RETURNING last_value`
+	err := r.db.QueryRowContext(ctx, query, prefix, len(prefix)).Scan(&next)
+	if err != nil {
+		fmt.Println("repository.product.NextSKUSequence() Query Error: ", err.Error())
+		return 0, err
+	}
+
+	return next, nil
+}
+
+// uniqueViolation turns a unique index violation on the SKU or barcode, which means
+// a concurrent write took the value after checkProductCodes ran, into
+// ErrDuplicateSKU or ErrDuplicateBarcode. Other errors are returned unchanged.
+func (r *productRepository) uniqueViolation(err error) error {
+	if !r.dialect.IsUniqueViolation(err) {
+		return err
+	}
+
+	switch {
+	case strings.Contains(err.Error(), "barcode"):
+		return ErrDuplicateBarcode
+	case strings.Contains(err.Error(), "sku"):
+		return ErrDuplicateSKU
+	default:
+		return err
+	}
+}
+
+// checkProductCodes returns ErrDuplicateSKU or ErrDuplicateBarcode when another
+// product, deleted ones included, already uses the SKU of p in any store or its
+// barcode in the store. The unique indexes still guard against a concurrent insert
+// slipping in between.
+func checkProductCodes(ctx context.Context, tx *sql.Tx, storeID int64, p model.Product) error {
+	var taken bool
+	query := "SELECT EXISTS (SELECT 1 FROM products WHERE sku = $1 AND uuid <> $2)"
+	err := tx.QueryRowContext(ctx, query, p.SKU, p.UUID).Scan(&taken)
+	if err != nil {
+		fmt.Println("repository.product.checkProductCodes() Query Error: ", err.Error())
+		return err
+	}
+	if taken {
+		return ErrDuplicateSKU
+	}
+
+	if p.Barcode == nil {
+		return nil
+	}
+
+	query = "SELECT EXISTS (SELECT 1 FROM products WHERE barcode = $1 AND store_id = $2 AND uuid <> $3)"
+	err = tx.QueryRowContext(ctx, query, *p.Barcode, storeID, p.UUID).Scan(&taken)
+	if err != nil {
+		fmt.Println("repository.product.checkProductCodes() Query Error: ", err.Error())
+		return err
+	}
+	if taken {
+		return ErrDuplicateBarcode
+	}
+
+	return nil
+}
+
+func (r *productRepository) DeleteProduct(ctx context.Context, storeID int64, uuid string, entry model.AuditEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		fmt.Println("repository.product.DeleteProduct() Begin Error: ", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE products SET deleted_at = $1 WHERE uuid = $2 AND store_id = $3 AND deleted_at IS NULL"
+	result, err := tx.ExecContext(ctx, query, time.Now().UTC(), uuid, storeID)
+	if err != nil {
+		fmt.Println("repository.product.DeleteProduct() Exec Error: ", err.Error())
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrProductNotFound
+	}
+
+	err = recordAuditEntry(ctx, tx, storeID, &entry)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		fmt.Println("repository.product.DeleteProduct() Commit Error: ", err.Error())
+	}
+
+	return err
+}
+
+// deletedProductColumns selects a deleted product with its category, when the
+// category still exists, for scanDeletedProduct.
+const deletedProductColumns = `
+		p.id, p.uuid, COALESCE(p.sku, ''), p.barcode, p.name, p.stock, p.price, p.deleted_at,
+		c.id, c.uuid, c.name, c.description
+	FROM products p
+	LEFT JOIN categories c ON p.category_id = c.id AND c.deleted_at IS NULL`
+
+// scanDeletedProduct scans a row selected with deletedProductColumns.
+func scanDeletedProduct(scan func(dest ...interface{}) error) (model.Product, error) {
+	var p model.Product
+	var categoryDBID sql.NullInt64
+	var categoryUUID, categoryName sql.NullString
+	var categoryDesc sql.NullString
+
+	err := scan(
+		&p.ID, &p.UUID, &p.SKU, &p.Barcode, &p.Name, &p.Stock, &p.Price, &p.DeletedAt,
+		&categoryDBID, &categoryUUID, &categoryName, &categoryDesc,
+	)
+	if err != nil {
+		return p, err
+	}
+
+	if categoryUUID.Valid && categoryName.Valid {
+		category := &model.Category{
+			ID:   categoryDBID.Int64,
+			UUID: categoryUUID.String,
+			Name: categoryName.String,
+		}
+		if categoryDesc.Valid {
+			category.Description = &categoryDesc.String
+		}
+		p.Category = category
+	}
+
+	return p, nil
+}
+
+func (r *productRepository) GetDeletedProducts(ctx context.Context, storeID int64, filter TrashFilter) ([]model.Product, error) {
+	query := "SELECT " + deletedProductColumns + " WHERE p.deleted_at IS NOT NULL AND p.store_id = $1"
+
+	args := []interface{}{storeID}
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.DeletedBefore != nil {
+		query += " AND p.deleted_at < " + addArg(filter.DeletedBefore.UTC())
+	}
+	if filter.BeforeID > 0 {
+		query += " AND p.id < " + addArg(filter.BeforeID)
+	}
+
+	query += " ORDER BY p.id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT " + addArg(filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		fmt.Println("repository.product.GetDeletedProducts() Query Error: ", err.Error())
 		return nil, err
 	}
 	defer rows.Close()
 
 	products := make([]model.Product, 0)
 	for rows.Next() {
-		var p model.Product
-		var categoryDBID sql.NullInt64
-		var categoryUUID, categoryName sql.NullString
-		var categoryDesc sql.NullString
-
-		err := rows.Scan(
-			&p.ID, &p.UUID, &p.SKU, &p.Name, &p.Stock, &p.Price,
-			&categoryDBID, &categoryUUID, &categoryName, &categoryDesc,
-		)
+		p, err := scanDeletedProduct(rows.Scan)
 		if err != nil {
-			fmt.Println("repository.product.GetProductBySKUs() Scan Error: ", err.Error())
+			fmt.Println("repository.product.GetDeletedProducts() Scan Error: ", err.Error())
 			return nil, err
 		}
-
-		if categoryUUID.Valid && categoryName.Valid {
-			category := &model.Category{
-				ID:   categoryDBID.Int64,
-				UUID: categoryUUID.String,
-				Name: categoryName.String,
-			}
-			if categoryDesc.Valid {
-				category.Description = &categoryDesc.String
-			}
-			p.Category = category
-		}
-
 		products = append(products, p)
 	}
 
-	return products, nil
+	return products, rows.Err()
 }
 
-func (r *ProductRepository) CreateProduct(ctx context.Context, p model.Product) error {
-	query := "INSERT INTO products (uuid, sku, name, stock, price, category_id) VALUES ($1, $2, $3, $4, $5, $6)"
-	_, err := r.db.ExecContext(ctx, query, p.UUID, p.SKU, p.Name, p.Stock, p.Price, p.Category.ID)
+func (r *productRepository) GetDeletedProductByUUID(ctx context.Context, storeID int64, uuid string) (*model.Product, error) {
+	if !helper.IsValidUUID(uuid) {
+		return nil, nil
+	}
+
+	query := "SELECT " + deletedProductColumns + " WHERE p.deleted_at IS NOT NULL AND p.uuid = $1 AND p.store_id = $2"
+	p, err := scanDeletedProduct(r.db.QueryRowContext(ctx, query, uuid, storeID).Scan)
 	if err != nil {
-		fmt.Println("repository.product.CreateProduct() Exec Error: ", err.Error())
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		fmt.Println("repository.product.GetDeletedProductByUUID() Scan Error: ", err.Error())
+		return nil, err
 	}
 
-	return err
+	return &p, nil
 }
 
-func (r *ProductRepository) UpdateProduct(ctx context.Context, p model.Product) error {
-	var categoryID *int64
-	if p.Category != nil {
-		categoryID = &p.Category.ID
+func (r *productRepository) RestoreProduct(ctx context.Context, storeID int64, uuid string, entry model.AuditEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		fmt.Println("repository.product.RestoreProduct() Begin Error: ", err.Error())
+		return err
 	}
+	defer tx.Rollback()
 
-	query := "UPDATE products SET name = $1, stock = $2, price = $3, category_id = $4, updated_at = NOW() WHERE uuid = $5"
-	_, err := r.db.ExecContext(ctx, query, p.Name, p.Stock, p.Price, categoryID, p.UUID)
+	query := "UPDATE products SET deleted_at = NULL, updated_at = " + r.dialect.Now() + " WHERE uuid = $1 AND store_id = $2 AND deleted_at IS NOT NULL"
+	result, err := tx.ExecContext(ctx, query, uuid, storeID)
 	if err != nil {
-		fmt.Println("repository.product.UpdateProduct() Exec Error: ", err.Error())
+		fmt.Println("repository.product.RestoreProduct() Exec Error: ", err.Error())
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrProductNotFound
+	}
+
+	err = recordAuditEntry(ctx, tx, storeID, &entry)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		fmt.Println("repository.product.RestoreProduct() Commit Error: ", err.Error())
 	}
 
 	return err
 }
 
-func (r *ProductRepository) DeleteProduct(ctx context.Context, uuid string) error {
-	query := "UPDATE products SET deleted_at = NOW() WHERE uuid = $1"
-	_, err := r.db.ExecContext(ctx, query, uuid)
+func (r *productRepository) PurgeProducts(ctx context.Context, storeID int64, entries []model.AuditEntry) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		fmt.Println("repository.product.DeleteProduct() Exec Error: ", err.Error())
+		fmt.Println("repository.product.PurgeProducts() Begin Error: ", err.Error())
+		return 0, err
 	}
+	defer tx.Rollback()
+
+	var purged int64
+	for _, entry := range entries {
+		var id int64
+		query := `SELECT p.id FROM products p
+			WHERE p.uuid = $1 AND p.store_id = $2 AND p.deleted_at IS NOT NULL
+				AND NOT EXISTS (SELECT 1 FROM transaction_details td WHERE td.product_id = p.id)`
+		err := tx.QueryRowContext(ctx, query, entry.EntityID, storeID).Scan(&id)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			fmt.Println("repository.product.PurgeProducts() Query Error: ", err.Error())
+			return 0, err
+		}
 
-	return err
+		_, err = tx.ExecContext(ctx, "DELETE FROM stock_movements WHERE product_id = $1", id)
+		if err != nil {
+			fmt.Println("repository.product.PurgeProducts() Delete Error: ", err.Error())
+			return 0, err
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM products WHERE id = $1", id)
+		if err != nil {
+			fmt.Println("repository.product.PurgeProducts() Delete Error: ", err.Error())
+			return 0, err
+		}
+
+		err = recordAuditEntry(ctx, tx, storeID, &entry)
+		if err != nil {
+			return 0, err
+		}
+		purged++
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		fmt.Println("repository.product.PurgeProducts() Commit Error: ", err.Error())
+		return 0, err
+	}
+
+	return purged, nil
 }
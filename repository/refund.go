@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/transport"
+	"fmt"
+	"time"
+)
+
+// PlanRefund works out which transaction detail lines a void or refund reverses.
+// details must carry their RefundedQuantity so nothing is refunded twice. Refunded
+// quantities of a product are taken from its detail lines in order.
+func PlanRefund(details []model.TransactionDetail, refundType string, req transport.RefundRequest) ([]model.RefundDetail, model.Money, error) {
+	var refundDetails []model.RefundDetail
+	var totalAmount model.Money
+
+	// Refunded quantities never exceed the sold ones, so neither can the amounts.
+	addLine := func(d model.TransactionDetail, qty int64) {
+		subTotal, _ := d.Price.Mul(qty)
+		totalAmount += subTotal
+		refundDetails = append(refundDetails, model.RefundDetail{
+			TransactionDetailID: d.ID,
+			ProductID:           d.ProductID,
+			ProductUUID:         d.ProductUUID,
+			ProductName:         d.ProductName,
+			Price:               d.Price,
+			Quantity:            qty,
+			SubTotal:            subTotal,
+		})
+	}
+
+	if refundType == model.RefundTypeVoid {
+		for _, d := range details {
+			remaining := d.Quantity - d.RefundedQuantity
+			if remaining > 0 {
+				addLine(d, remaining)
+			}
+		}
+		if len(refundDetails) == 0 {
+			return nil, 0, ErrNothingToRefund
+		}
+
+		return refundDetails, totalAmount, nil
+	}
+
+	if len(req.Items) == 0 {
+		return nil, 0, NewValidationError(ErrInvalidRefund, "items", "must contain at least one item")
+	}
+
+	// Quantities already allocated by this refund, per detail line index.
+	allocated := make([]int64, len(details))
+	for idx, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, 0, NewValidationError(ErrInvalidRefund, fmt.Sprintf("items[%d].quantity", idx), "must be greater than zero")
+		}
+
+		found := false
+		left := item.Quantity
+		for i, d := range details {
+			if d.ProductUUID != item.ProductID {
+				continue
+			}
+			found = true
+
+			remaining := d.Quantity - d.RefundedQuantity - allocated[i]
+			if remaining <= 0 {
+				continue
+			}
+			qty := min(left, remaining)
+			allocated[i] += qty
+			left -= qty
+			if left == 0 {
+				break
+			}
+		}
+
+		if !found {
+			return nil, 0, NewValidationError(ErrInvalidRefund, fmt.Sprintf("items[%d].product_id", idx), "is not part of this transaction")
+		}
+		if left > 0 {
+			return nil, 0, NewValidationError(ErrInvalidRefund, fmt.Sprintf("items[%d].quantity", idx), fmt.Sprintf("exceeds the %d unit(s) left to refund", item.Quantity-left))
+		}
+	}
+
+	for i, d := range details {
+		if allocated[i] > 0 {
+			addLine(d, allocated[i])
+		}
+	}
+
+	return refundDetails, totalAmount, nil
+}
+
+func (r *checkoutRepository) CreateRefund(ctx context.Context, storeID int64, transactionUUID string, refundType string, req transport.RefundRequest) (*model.Refund, error) {
+	if !helper.IsValidUUID(transactionUUID) {
+		return nil, ErrTransactionNotFound
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		fmt.Println("repository.refund.CreateRefund() Begin Error: ", err.Error())
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the transaction so concurrent refunds of it cannot both pass the checks.
+	var transactionID int64
+	query := "SELECT id FROM transactions WHERE uuid = $1 AND store_id = $2" + r.dialect.ForUpdate()
+	err = tx.QueryRowContext(ctx, query, transactionUUID, storeID).Scan(&transactionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTransactionNotFound
+		}
+		fmt.Println("repository.refund.CreateRefund() Scan Error: ", err.Error())
+		return nil, err
+	}
+
+	query = `SELECT 
+			td.id, td.transaction_id, td.product_id, COALESCE(p.uuid, ''), td.name, td.price, td.quantity, td.subtotal,
+			COALESCE((SELECT SUM(rd.quantity) FROM refund_details rd WHERE rd.transaction_detail_id = td.id), 0)
+		FROM transaction_details td
+		LEFT JOIN products p ON p.id = td.product_id
+		WHERE td.transaction_id = $1
+		ORDER BY td.id ASC`
+	rows, err := tx.QueryContext(ctx, query, transactionID)
+	if err != nil {
+		fmt.Println("repository.refund.CreateRefund() Query Error: ", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var details []model.TransactionDetail
+	for rows.Next() {
+		var d model.TransactionDetail
+		err := rows.Scan(&d.ID, &d.TransactionID, &d.ProductID, &d.ProductUUID, &d.ProductName, &d.Price, &d.Quantity, &d.SubTotal, &d.RefundedQuantity)
+		if err != nil {
+			fmt.Println("repository.refund.CreateRefund() Scan Error: ", err.Error())
+			return nil, err
+		}
+		details = append(details, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	refundDetails, totalAmount, err := PlanRefund(details, refundType, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lock the restocked products in ID order, as checkouts do, so a refund and a
+	// checkout of the same products cannot deadlock.
+	var productIDs []interface{}
+	seen := make(map[int64]bool, len(refundDetails))
+	for _, d := range refundDetails {
+		if !seen[d.ProductID] {
+			seen[d.ProductID] = true
+			productIDs = append(productIDs, d.ProductID)
+		}
+	}
+	query = fmt.Sprintf("SELECT id FROM products WHERE id IN (%s) ORDER BY id%s", placeholders(1, len(productIDs)), r.dialect.ForUpdate())
+	lockRows, err := tx.QueryContext(ctx, query, productIDs...)
+	if err != nil {
+		fmt.Println("repository.refund.CreateRefund() Lock Error: ", err.Error())
+		return nil, err
+	}
+	defer lockRows.Close()
+	for lockRows.Next() {
+	}
+	if err := lockRows.Err(); err != nil {
+		return nil, err
+	}
+
+	refund := model.Refund{
+		UUID:          helper.GenerateUUID(),
+		TransactionID: transactionID,
+		Type:          refundType,
+		Reason:        req.Reason,
+		TotalAmount:   totalAmount,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	query = "INSERT INTO refunds (uuid, transaction_id, type, reason, total_amount, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+	err = tx.QueryRowContext(ctx, query, refund.UUID, refund.TransactionID, refund.Type, refund.Reason, refund.TotalAmount, refund.CreatedAt).Scan(&refund.ID)
+	if err != nil {
+		fmt.Println("repository.refund.CreateRefund() Insert Error: ", err.Error())
+		return nil, err
+	}
+
+	for i, d := range refundDetails {
+		query = "INSERT INTO refund_details (refund_id, transaction_detail_id, product_id, name, price, quantity, subtotal) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
+		err = tx.QueryRowContext(ctx, query, refund.ID, d.TransactionDetailID, d.ProductID, d.ProductName, d.Price, d.Quantity, d.SubTotal).Scan(&refundDetails[i].ID)
+		if err != nil {
+			fmt.Println("repository.refund.CreateRefund() Insert Detail Error: ", err.Error())
+			return nil, err
+		}
+		refundDetails[i].RefundID = refund.ID
+
+		// Products without stock tracking keep a NULL stock and get no ledger entry.
+		var balance int64
+		query = "UPDATE products SET stock = stock + $1 WHERE id = $2 AND stock IS NOT NULL RETURNING stock"
+		err = tx.QueryRowContext(ctx, query, d.Quantity, d.ProductID).Scan(&balance)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			fmt.Println("repository.refund.CreateRefund() Restock Error: ", err.Error())
+			return nil, err
+		}
+
+		err = recordStockMovement(ctx, tx, &model.StockMovement{
+			ProductID: d.ProductID,
+			Type:      model.StockMovementRefund,
+			Delta:     d.Quantity,
+			Balance:   balance,
+			Reference: refund.UUID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		fmt.Println("repository.refund.CreateRefund() Commit Error: ", err.Error())
+		return nil, err
+	}
+
+	refund.Details = refundDetails
+	return &refund, nil
+}
+
+// attachRefunds loads the refunds of the given transactions.
+func (r *checkoutRepository) attachRefunds(ctx context.Context, transactions []model.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(transactions))
+	index := make(map[int64]int, len(transactions))
+	for i, t := range transactions {
+		args[i] = t.ID
+		index[t.ID] = i
+		transactions[i].Refunds = make([]model.Refund, 0)
+	}
+
+	query := fmt.Sprintf(
+		`SELECT 
+			rf.id, rf.uuid, rf.transaction_id, rf.type, rf.reason, rf.total_amount, rf.created_at,
+			rd.id, rd.transaction_detail_id, rd.product_id, COALESCE(p.uuid, ''), rd.name, rd.price, rd.quantity, rd.subtotal
+		FROM refunds rf
+		JOIN refund_details rd ON rd.refund_id = rf.id
+		LEFT JOIN products p ON p.id = rd.product_id
+		WHERE rf.transaction_id IN (%s)
+		ORDER BY rf.id ASC, rd.id ASC`,
+		placeholders(1, len(args)),
+	)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		fmt.Println("repository.refund.attachRefunds() Query Error: ", err.Error())
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rf model.Refund
+		var d model.RefundDetail
+		err := rows.Scan(
+			&rf.ID, &rf.UUID, &rf.TransactionID, &rf.Type, &rf.Reason, &rf.TotalAmount, &rf.CreatedAt,
+			&d.ID, &d.TransactionDetailID, &d.ProductID, &d.ProductUUID, &d.ProductName, &d.Price, &d.Quantity, &d.SubTotal,
+		)
+		if err != nil {
+			fmt.Println("repository.refund.attachRefunds() Scan Error: ", err.Error())
+			return err
+		}
+		d.RefundID = rf.ID
+
+		t := &transactions[index[rf.TransactionID]]
+		if n := len(t.Refunds); n == 0 || t.Refunds[n-1].ID != rf.ID {
+			t.Refunds = append(t.Refunds, rf)
+		}
+		last := &t.Refunds[len(t.Refunds)-1]
+		last.Details = append(last.Details, d)
+	}
+
+	return rows.Err()
+}
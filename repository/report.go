@@ -1,54 +1,245 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"fendi/modul-03-task/database"
 	"fendi/modul-03-task/model"
+	"fmt"
 )
 
-type ReportRepository struct {
-	db *sql.DB
+type reportRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
 }
 
-func NewReportRepository(db *sql.DB) *ReportRepository {
-	return &ReportRepository{db: db}
+func NewReportRepository(db *sql.DB, dialect database.Dialect) ReportRepository {
+	return &reportRepository{db: db, dialect: dialect}
 }
 
-func (r *ReportRepository) FetchReport(dateStart, dateEnd string) (model.ReportData, error) {
+// Timestamps are written in UTC, so the report bounds are compared in UTC too.
+
+// reportMovements lists every sold and refunded line of the period as store_id,
+// product_id, quantity, revenue and the transaction_id of sales, refunds negated and
+// without a transaction. Refunds count in the period they were made, not when the
+// sale happened.
+const reportMovements = `
+	SELECT t.store_id, td.product_id, td.quantity, td.subtotal AS revenue, td.transaction_id
+	FROM transaction_details td
+	JOIN transactions t ON t.id = td.transaction_id
+	WHERE td.purchased_at >= $1 AND td.purchased_at < $2
+	UNION ALL
+	SELECT t.store_id, rd.product_id, -rd.quantity, -rd.subtotal, NULL
+	FROM refund_details rd
+	JOIN refunds rf ON rf.id = rd.refund_id
+	JOIN transactions t ON t.id = rf.transaction_id
+	WHERE rf.created_at >= $1 AND rf.created_at < $2
+`
+
+// The per-store reports pass the store ID as $3.
+
+// FetchReport aggregates sales from filter.From up to filter.To. Totals are
+// taken over all transactions of the period; the best seller lists hold at most
+// filter.Top products with a positive quantity or revenue.
+func (r *reportRepository) FetchReport(ctx context.Context, storeID int64, filter ReportFilter) (model.ReportData, error) {
 	var report model.ReportData
 
 	query := `
-		SELECT 
-			COALESCE(SUM(t.total_amount), 0) AS total_revenue,
-			COUNT(t.id) AS total_transaction,
-			COALESCE(p.uuid::text, '') AS most_purchased_product_id,
-			COALESCE(p.name, '') AS most_purchased_product_name,
-			COALESCE(SUM(td.quantity), 0) AS most_purchased_quantity
-		FROM 
-			transactions t
-		LEFT JOIN 
-			transaction_details td ON t.id = td.transaction_id
-		LEFT JOIN 
-			products p ON td.product_id = p.id
-		WHERE 
-			t.purchased_at BETWEEN $1 AND $2
-		GROUP BY 
-			p.id
-		ORDER BY 
-			most_purchased_quantity DESC
-		LIMIT 1;
+		SELECT
+			COALESCE((
+				SELECT SUM(t.total_amount) FROM transactions t
+				WHERE t.store_id = $3 AND t.purchased_at >= $1 AND t.purchased_at < $2
+			), 0) AS gross_sales,
+			COALESCE((
+				SELECT SUM(rf.total_amount) FROM refunds rf
+				JOIN transactions t ON t.id = rf.transaction_id
+				WHERE t.store_id = $3 AND rf.created_at >= $1 AND rf.created_at < $2
+			), 0) AS refunded,
+			(
+				SELECT COUNT(t.id) FROM transactions t
+				WHERE t.store_id = $3 AND t.purchased_at >= $1 AND t.purchased_at < $2
+			) AS total_transaction,
+			COALESCE((
+				SELECT SUM(m.quantity) FROM (` + reportMovements + `) m WHERE m.store_id = $3
+			), 0) AS items_sold
 	`
 
-	row := r.db.QueryRow(query, dateStart, dateEnd)
-	err := row.Scan(
-		&report.TotalRevenue,
-		&report.TotalTransaction,
-		&report.MostPurchasedItem.ProductID,
-		&report.MostPurchasedItem.ProductName,
-		&report.MostPurchasedItem.Quantity,
-	)
+	var refunded model.Money
+	row := r.db.QueryRowContext(ctx, query, filter.From.UTC(), filter.To.UTC(), storeID)
+	err := row.Scan(&report.GrossSales, &refunded, &report.TotalTransaction, &report.ItemsSold)
+	if err != nil {
+		fmt.Println("repository.report.FetchReport() Query Error: ", err.Error())
+		return model.ReportData{}, err
+	}
+	report.TotalRevenue = report.GrossSales - refunded
+
+	report.TopByQuantity, err = r.topProducts(ctx, storeID, filter, "quantity", "revenue")
+	if err != nil {
+		return model.ReportData{}, err
+	}
+	report.TopByRevenue, err = r.topProducts(ctx, storeID, filter, "revenue", "quantity")
 	if err != nil {
 		return model.ReportData{}, err
 	}
 
 	return report, nil
 }
+
+// topProducts returns the best sellers of the period ranked by the by column of
+// reportMovements, ties broken by the then column and the oldest product first.
+func (r *reportRepository) topProducts(ctx context.Context, storeID int64, filter ReportFilter, by, then string) ([]model.ProductSales, error) {
+	query := `
+		SELECT p.uuid, p.name, SUM(m.quantity), SUM(m.revenue)
+		FROM (` + reportMovements + `) m
+		JOIN products p ON m.product_id = p.id
+		WHERE m.store_id = $3
+		GROUP BY p.id, p.uuid, p.name
+		HAVING SUM(m.` + by + `) > 0
+		ORDER BY SUM(m.` + by + `) DESC, SUM(m.` + then + `) DESC, p.id ASC
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.From.UTC(), filter.To.UTC(), storeID, filter.Top)
+	if err != nil {
+		fmt.Println("repository.report.topProducts() Query Error: ", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []model.ProductSales{}
+	for rows.Next() {
+		var p model.ProductSales
+		err = rows.Scan(&p.ProductID, &p.ProductName, &p.Quantity, &p.Revenue)
+		if err != nil {
+			fmt.Println("repository.report.topProducts() Scan Error: ", err.Error())
+			return nil, err
+		}
+		products = append(products, p)
+	}
+
+	return products, rows.Err()
+}
+
+// FetchSalesEntries lists every transaction of the period with its units sold, and
+// every refund made in the period with its total and units negated, oldest first.
+func (r *reportRepository) FetchSalesEntries(ctx context.Context, storeID int64, filter ReportFilter) ([]model.SalesEntry, error) {
+	query := `
+		SELECT t.purchased_at, t.total_amount, 1, COALESCE((
+			SELECT SUM(td.quantity) FROM transaction_details td WHERE td.transaction_id = t.id
+		), 0)
+		FROM transactions t
+		WHERE t.store_id = $3 AND t.purchased_at >= $1 AND t.purchased_at < $2
+		UNION ALL
+		SELECT rf.created_at, -rf.total_amount, 0, -COALESCE((
+			SELECT SUM(rd.quantity) FROM refund_details rd WHERE rd.refund_id = rf.id
+		), 0)
+		FROM refunds rf
+		JOIN transactions t ON t.id = rf.transaction_id
+		WHERE t.store_id = $3 AND rf.created_at >= $1 AND rf.created_at < $2
+		ORDER BY 1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.From.UTC(), filter.To.UTC(), storeID)
+	if err != nil {
+		fmt.Println("repository.report.FetchSalesEntries() Query Error: ", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []model.SalesEntry
+	for rows.Next() {
+		var e model.SalesEntry
+		err = rows.Scan(&e.At, &e.Revenue, &e.Transactions, &e.ItemsSold)
+		if err != nil {
+			fmt.Println("repository.report.FetchSalesEntries() Scan Error: ", err.Error())
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// FetchCategorySales joins the lines of the period to their products and categories.
+// Products without a category form one group with a NULL category; soft-deleted
+// products and categories are still counted.
+func (r *reportRepository) FetchCategorySales(ctx context.Context, storeID int64, filter ReportFilter) ([]model.CategorySales, error) {
+	query := `
+		SELECT
+			c.uuid, COALESCE(c.name, ''), c.deleted_at IS NOT NULL,
+			SUM(m.quantity), SUM(m.revenue), COUNT(DISTINCT m.transaction_id)
+		FROM (` + reportMovements + `) m
+		JOIN products p ON m.product_id = p.id
+		LEFT JOIN categories c ON p.category_id = c.id
+		WHERE m.store_id = $3
+		GROUP BY c.id, c.uuid, c.name, c.deleted_at
+		ORDER BY SUM(m.revenue) DESC, COALESCE(c.name, '') ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.From.UTC(), filter.To.UTC(), storeID)
+	if err != nil {
+		fmt.Println("repository.report.FetchCategorySales() Query Error: ", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []model.CategorySales
+	for rows.Next() {
+		var c model.CategorySales
+		err = rows.Scan(&c.CategoryID, &c.CategoryName, &c.Deleted, &c.Quantity, &c.Revenue, &c.Transactions)
+		if err != nil {
+			fmt.Println("repository.report.FetchCategorySales() Scan Error: ", err.Error())
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+// FetchStoreSales sums the transactions, refunds and units of the period per store.
+func (r *reportRepository) FetchStoreSales(ctx context.Context, filter ReportFilter) ([]model.StoreSales, error) {
+	query := `
+		SELECT
+			s.id, s.uuid, s.name, s.address, s.created_at,
+			COALESCE((
+				SELECT SUM(t.total_amount) FROM transactions t
+				WHERE t.store_id = s.id AND t.purchased_at >= $1 AND t.purchased_at < $2
+			), 0),
+			COALESCE((
+				SELECT SUM(rf.total_amount) FROM refunds rf
+				JOIN transactions t ON t.id = rf.transaction_id
+				WHERE t.store_id = s.id AND rf.created_at >= $1 AND rf.created_at < $2
+			), 0),
+			(
+				SELECT COUNT(t.id) FROM transactions t
+				WHERE t.store_id = s.id AND t.purchased_at >= $1 AND t.purchased_at < $2
+			),
+			COALESCE((
+				SELECT SUM(m.quantity) FROM (` + reportMovements + `) m WHERE m.store_id = s.id
+			), 0)
+		FROM stores s
+		ORDER BY s.id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.From.UTC(), filter.To.UTC())
+	if err != nil {
+		fmt.Println("repository.report.FetchStoreSales() Query Error: ", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stores []model.StoreSales
+	for rows.Next() {
+		var s model.StoreSales
+		err = rows.Scan(&s.Store.ID, &s.Store.UUID, &s.Store.Name, &s.Store.Address, &s.Store.CreatedAt,
+			&s.GrossSales, &s.Refunded, &s.Transactions, &s.ItemsSold)
+		if err != nil {
+			fmt.Println("repository.report.FetchStoreSales() Scan Error: ", err.Error())
+			return nil, err
+		}
+		stores = append(stores, s)
+	}
+
+	return stores, rows.Err()
+}
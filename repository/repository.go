@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/transport"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StoreRepository is the storage contract for the stores of the deployment.
+type StoreRepository interface {
+	// GetAllStore lists every store, oldest first.
+	GetAllStore(ctx context.Context) ([]model.Store, error)
+	GetStoreByUUID(ctx context.Context, uuid string) (*model.Store, error)
+	CreateStore(ctx context.Context, s model.Store) error
+	UpdateStore(ctx context.Context, s model.Store) error
+}
+
+// The category, product, checkout, stock and report repositories work inside one
+// store: every method taking a storeID only sees and changes the rows of that store.
+
+// CategoryRepository is the storage contract for categories.
+type CategoryRepository interface {
+	GetAllCategory(ctx context.Context, storeID int64, filter CategoryFilter) ([]model.Category, error)
+	CountCategory(ctx context.Context, storeID int64, filter CategoryFilter) (int64, error)
+	GetCategoryByUUID(ctx context.Context, storeID int64, uuid string) (*model.Category, error)
+	CreateCategory(ctx context.Context, storeID int64, c model.Category) error
+	// UpdateCategory and DeleteCategory record entry in the audit trail together with
+	// the change.
+	UpdateCategory(ctx context.Context, storeID int64, c model.Category, entry model.AuditEntry) error
+	DeleteCategory(ctx context.Context, storeID int64, uuid string, entry model.AuditEntry) error
+
+	// GetDeletedCategories lists the deleted categories of the store, newest first.
+	GetDeletedCategories(ctx context.Context, storeID int64, filter TrashFilter) ([]model.Category, error)
+	GetDeletedCategoryByUUID(ctx context.Context, storeID int64, uuid string) (*model.Category, error)
+	// RestoreCategory undeletes a category and records entry in the audit trail.
+	RestoreCategory(ctx context.Context, storeID int64, uuid string, entry model.AuditEntry) error
+	// PurgeCategories removes the deleted categories named by the EntityID of entries
+	// for good and records the entries. Products still in them lose their category.
+	// It returns how many categories were removed.
+	PurgeCategories(ctx context.Context, storeID int64, entries []model.AuditEntry) (int64, error)
+}
+
+// ProductRepository is the storage contract for products. SKUs are unique across
+// stores and barcodes within a store.
+type ProductRepository interface {
+	GetAllProduct(ctx context.Context, storeID int64, filter ProductFilter) ([]model.Product, error)
+	CountProduct(ctx context.Context, storeID int64, filter ProductFilter) (int64, error)
+	GetProductByUUID(ctx context.Context, storeID int64, uuid string) (*model.Product, error)
+	// NextSKUSequence hands out the next {SEQ} number for SKUs starting with prefix.
+	// Concurrent callers never get the same number. The first number of a prefix
+	// follows the products of every store, deleted ones included, already using it.
+	NextSKUSequence(ctx context.Context, prefix string) (int64, error)
+	CreateProduct(ctx context.Context, storeID int64, p model.Product) error
+	// UpdateProduct and DeleteProduct record entry in the audit trail together with
+	// the change.
+	UpdateProduct(ctx context.Context, storeID int64, p model.Product, entry model.AuditEntry) error
+	DeleteProduct(ctx context.Context, storeID int64, uuid string, entry model.AuditEntry) error
+
+	// GetDeletedProducts lists the deleted products of the store, newest first.
+	GetDeletedProducts(ctx context.Context, storeID int64, filter TrashFilter) ([]model.Product, error)
+	GetDeletedProductByUUID(ctx context.Context, storeID int64, uuid string) (*model.Product, error)
+	// RestoreProduct undeletes a product and records entry in the audit trail.
+	RestoreProduct(ctx context.Context, storeID int64, uuid string, entry model.AuditEntry) error
+	// PurgeProducts removes the deleted products named by the EntityID of entries for
+	// good, with their stock ledger, and records the entries of the removed ones.
+	// Products that appear on a transaction are kept for the sales history. It returns
+	// how many products were removed.
+	PurgeProducts(ctx context.Context, storeID int64, entries []model.AuditEntry) (int64, error)
+}
+
+// TrashFilter narrows down a listing of deleted products or categories. Nil and zero
+// fields are not applied.
+type TrashFilter struct {
+	DeletedBefore *time.Time // exclusive
+	// BeforeID is the pagination cursor, only records with a lower ID are returned.
+	BeforeID int64
+	Limit    int
+}
+
+// Sort fields for product and category listings. SortByCreated orders by creation,
+// which follows the row ID.
+const (
+	SortByCreated = "created"
+	SortByName    = "name"
+	SortByPrice   = "price"
+	SortByStock   = "stock"
+)
+
+// Stock filters for product listings.
+const (
+	StockFilterInStock    = "in_stock"     // tracked stock above zero
+	StockFilterOutOfStock = "out_of_stock" // tracked stock at zero
+	StockFilterUnlimited  = "unlimited"    // stock not tracked
+)
+
+// Sort keys of products without a price or without stock tracking. Unpriced products
+// sort as free and unlimited stock sorts above any tracked stock.
+const (
+	unpricedSortKey  = model.Money(0)
+	unlimitedSortKey = int64(2147483647)
+)
+
+// ListCursor marks the last row of the previous page of a sorted listing. Value is the
+// sort key of that row (a string for SortByName, a model.Money for SortByPrice, an int64
+// for SortByStock and unused for SortByCreated) and ID breaks ties.
+type ListCursor struct {
+	Value interface{}
+	ID    int64
+}
+
+// CategoryFilter narrows down and orders a category listing. Zero fields are not applied.
+// Rows after After are returned when it is set, otherwise Offset rows are skipped.
+type CategoryFilter struct {
+	Keyword string
+	Sort    string
+	Desc    bool
+	After   *ListCursor
+	Offset  int
+	Limit   int
+}
+
+// ProductFilter narrows down and orders a product listing. Nil and zero fields are not
+// applied. Rows after After are returned when it is set, otherwise Offset rows are skipped.
+type ProductFilter struct {
+	Keyword      string
+	CategoryUUID string
+	SKU          string
+	Barcode      string
+	MinPrice     *model.Money
+	MaxPrice     *model.Money
+	Stock        string
+	Sort         string
+	Desc         bool
+	After        *ListCursor
+	Offset       int
+	Limit        int
+}
+
+// ProductSortKey returns the value p is ordered by for the given sort field.
+func ProductSortKey(p model.Product, sort string) interface{} {
+	switch sort {
+	case SortByName:
+		return p.Name
+	case SortByPrice:
+		if p.Price == nil {
+			return unpricedSortKey
+		}
+		return *p.Price
+	case SortByStock:
+		if p.Stock == nil {
+			return unlimitedSortKey
+		}
+		return *p.Stock
+	default:
+		return nil
+	}
+}
+
+// CategorySortKey returns the value c is ordered by for the given sort field.
+func CategorySortKey(c model.Category, sort string) interface{} {
+	if sort == SortByName {
+		return c.Name
+	}
+
+	return nil
+}
+
+// CheckoutRepository is the storage contract for checkout transactions.
+// In strict mode the whole checkout is rejected with a *StockError when any item
+// cannot be fulfilled in full.
+type CheckoutRepository interface {
+	CreateCheckoutTransaction(ctx context.Context, storeID int64, req transport.CheckoutRequest, strict bool) (*model.Transaction, error)
+	GetAllTransaction(ctx context.Context, storeID int64, filter TransactionFilter) ([]model.Transaction, error)
+	GetTransactionByUUID(ctx context.Context, storeID int64, uuid string) (*model.Transaction, error)
+	// CreateRefund reverses items of a transaction and restores their stock. refundType
+	// is model.RefundTypeVoid to reverse everything still refundable, or
+	// model.RefundTypeRefund to reverse the requested items.
+	CreateRefund(ctx context.Context, storeID int64, transactionUUID string, refundType string, req transport.RefundRequest) (*model.Refund, error)
+}
+
+// TransactionFilter narrows down a transaction listing. Nil and zero fields are not applied.
+// Transactions are returned newest first.
+type TransactionFilter struct {
+	From        *time.Time // inclusive
+	To          *time.Time // exclusive
+	MinAmount   *model.Money
+	MaxAmount   *model.Money
+	ProductUUID string
+	// BeforeID is the pagination cursor, only transactions with a lower ID are returned.
+	BeforeID int64
+	Limit    int
+}
+
+// ReportFilter selects the period of a sales report and the length of its best
+// seller lists.
+type ReportFilter struct {
+	From time.Time // inclusive
+	To   time.Time // exclusive
+	Top  int
+}
+
+// ReportRepository is the storage contract for sales reports.
+type ReportRepository interface {
+	FetchReport(ctx context.Context, storeID int64, filter ReportFilter) (model.ReportData, error)
+	// FetchSalesEntries lists the sales and refunds of the period in time order. Top
+	// is ignored.
+	FetchSalesEntries(ctx context.Context, storeID int64, filter ReportFilter) ([]model.SalesEntry, error)
+	// FetchCategorySales groups the sales of the period by the current category of each
+	// product, highest revenue first. Top is ignored.
+	FetchCategorySales(ctx context.Context, storeID int64, filter ReportFilter) ([]model.CategorySales, error)
+	// FetchStoreSales lists the sales of the period of every store, stores without
+	// sales included, oldest store first. Top is ignored.
+	FetchStoreSales(ctx context.Context, filter ReportFilter) ([]model.StoreSales, error)
+}
+
+// StockRepository is the storage contract for the inventory movement ledger.
+type StockRepository interface {
+	// AdjustStock applies m.Delta to the product stock and records m in the ledger
+	// with the resulting balance. A balance above model.MaxStock is rejected with
+	// ErrInvalidStockAdjustment.
+	AdjustStock(ctx context.Context, storeID int64, productUUID string, m model.StockMovement) (*model.StockMovement, error)
+	// GetStockMovements lists the ledger of a product newest first. beforeID is the
+	// pagination cursor, zero starts at the newest entry.
+	GetStockMovements(ctx context.Context, storeID int64, productUUID string, beforeID int64, limit int) ([]model.StockMovement, error)
+}
+
+// AuditRepository is the storage contract for the audit trail. Entries are written
+// by the repositories whose changes they record.
+type AuditRepository interface {
+	GetAuditEntries(ctx context.Context, storeID int64, filter AuditFilter) ([]model.AuditEntry, error)
+}
+
+// AuditFilter narrows down an audit trail listing. Nil and zero fields are not applied.
+// Entries are returned newest first.
+type AuditFilter struct {
+	EntityType string
+	EntityID   string
+	Action     string
+	From       *time.Time // inclusive
+	To         *time.Time // exclusive
+	// BeforeID is the pagination cursor, only entries with a lower ID are returned.
+	BeforeID int64
+	Limit    int
+}
+
+// IdempotencyRepository is the storage contract for Idempotency-Key records.
+type IdempotencyRepository interface {
+	// ReserveIdempotencyKey stores rec as an in-progress key. When the key is already
+	// taken it stores nothing and returns the existing record instead.
+	ReserveIdempotencyKey(ctx context.Context, rec model.IdempotencyRecord) (*model.IdempotencyRecord, error)
+	CompleteIdempotencyKey(ctx context.Context, rec model.IdempotencyRecord) error
+	DeleteIdempotencyKey(ctx context.Context, scope, key string) error
+}
+
+// UserRepository is the storage contract for users and their API keys. Deleted
+// users are left out everywhere, and their keys no longer authenticate. Users are
+// returned with their store.
+type UserRepository interface {
+	// GetAllUser lists the live users, oldest first.
+	GetAllUser(ctx context.Context) ([]model.User, error)
+	GetUserByUUID(ctx context.Context, uuid string) (*model.User, error)
+	CountUserByRole(ctx context.Context, role string) (int64, error)
+	CreateUser(ctx context.Context, u model.User) error
+	UpdateUser(ctx context.Context, u model.User) error
+	DeleteUser(ctx context.Context, uuid string) error
+
+	// GetAPIKeysByUser lists the keys of a user, revoked ones included, oldest first.
+	GetAPIKeysByUser(ctx context.Context, userID int64) ([]model.APIKey, error)
+	GetAPIKeyByUUID(ctx context.Context, uuid string) (*model.APIKey, error)
+	CreateAPIKey(ctx context.Context, k model.APIKey) error
+	RevokeAPIKey(ctx context.Context, uuid string, at time.Time) error
+	// GetUserByAPIKeyHash finds the unrevoked key with hash and its live user, or
+	// returns nil for both.
+	GetUserByAPIKeyHash(ctx context.Context, hash string) (*model.User, *model.APIKey, error)
+	// TouchAPIKey records that a key was used at a time.
+	TouchAPIKey(ctx context.Context, id int64, at time.Time) error
+}
+
+// placeholders builds a comma separated list of count positional placeholders
+// starting at $start, e.g. "$1, $2, $3", for use in IN clauses.
+func placeholders(start, count int) string {
+	list := make([]string, count)
+	for i := range list {
+		list[i] = fmt.Sprintf("$%d", start+i)
+	}
+
+	return strings.Join(list, ", ")
+}
+
+// keysetCondition builds the WHERE clause that keeps only rows after cursor in a listing
+// ordered by sortExpr and then idExpr. An empty sortExpr orders by idExpr alone.
+func keysetCondition(sortExpr, idExpr string, desc bool, cursor ListCursor, args []interface{}) (string, []interface{}) {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+
+	if sortExpr == "" {
+		args = append(args, cursor.ID)
+		return fmt.Sprintf("%s %s $%d", idExpr, op, len(args)), args
+	}
+
+	args = append(args, cursor.Value, cursor.ID)
+	v, id := len(args)-1, len(args)
+	return fmt.Sprintf("(%s %s $%d OR (%s = $%d AND %s %s $%d))", sortExpr, op, v, sortExpr, v, idExpr, op, id), args
+}
+
+// orderBy builds the ORDER BY clause matching keysetCondition.
+func orderBy(sortExpr, idExpr string, desc bool) string {
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+
+	if sortExpr == "" {
+		return " ORDER BY " + idExpr + " " + dir
+	}
+	return " ORDER BY " + sortExpr + " " + dir + ", " + idExpr + " " + dir
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fendi/modul-03-task/database"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fmt"
+	"time"
+)
+
+type stockRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+func NewStockRepository(db *sql.DB, dialect database.Dialect) StockRepository {
+	return &stockRepository{db: db, dialect: dialect}
+}
+
+func (r *stockRepository) AdjustStock(ctx context.Context, storeID int64, productUUID string, m model.StockMovement) (*model.StockMovement, error) {
+	if !helper.IsValidUUID(productUUID) {
+		return nil, ErrProductNotFound
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		fmt.Println("repository.stock.AdjustStock() Begin Error: ", err.Error())
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var stock *int64
+	query := "SELECT id, stock FROM products WHERE uuid = $1 AND store_id = $2 AND deleted_at IS NULL" + r.dialect.ForUpdate()
+	err = tx.QueryRowContext(ctx, query, productUUID, storeID).Scan(&m.ProductID, &stock)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrProductNotFound
+		}
+		fmt.Println("repository.stock.AdjustStock() Scan Error: ", err.Error())
+		return nil, err
+	}
+	if stock == nil {
+		return nil, ErrStockNotTracked
+	}
+	if *stock+m.Delta > model.MaxStock {
+		return nil, NewValidationError(ErrInvalidStockAdjustment, "delta", fmt.Sprintf("would take stock above %d", model.MaxStock))
+	}
+
+	query = "UPDATE products SET stock = stock + $1, updated_at = " + r.dialect.Now() + " WHERE id = $2 AND stock + $1 >= 0 RETURNING stock"
+	err = tx.QueryRowContext(ctx, query, m.Delta, m.ProductID).Scan(&m.Balance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNegativeStock
+		}
+		fmt.Println("repository.stock.AdjustStock() Update Error: ", err.Error())
+		return nil, err
+	}
+
+	m.ProductUUID = productUUID
+	err = recordStockMovement(ctx, tx, &m)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		fmt.Println("repository.stock.AdjustStock() Commit Error: ", err.Error())
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+func (r *stockRepository) GetStockMovements(ctx context.Context, storeID int64, productUUID string, beforeID int64, limit int) ([]model.StockMovement, error) {
+	query := `SELECT 
+			sm.id, sm.product_id, p.uuid, sm.type, sm.delta, sm.balance, sm.reference, sm.note, sm.created_at
+		FROM stock_movements sm
+		JOIN products p ON p.id = sm.product_id
+		WHERE p.uuid = $1 AND p.store_id = $2`
+
+	args := []interface{}{productUUID, storeID}
+	if beforeID > 0 {
+		args = append(args, beforeID)
+		query += fmt.Sprintf(" AND sm.id < $%d", len(args))
+	}
+
+	query += " ORDER BY sm.id DESC"
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		fmt.Println("repository.stock.GetStockMovements() Query Error: ", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := make([]model.StockMovement, 0)
+	for rows.Next() {
+		var m model.StockMovement
+		err := rows.Scan(&m.ID, &m.ProductID, &m.ProductUUID, &m.Type, &m.Delta, &m.Balance, &m.Reference, &m.Note, &m.CreatedAt)
+		if err != nil {
+			fmt.Println("repository.stock.GetStockMovements() Scan Error: ", err.Error())
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+
+	return movements, rows.Err()
+}
+
+// recordStockMovement appends m to the ledger inside tx. m.ProductID, Type, Delta,
+// Balance and Reference must be set; ID and CreatedAt are filled in.
+func recordStockMovement(ctx context.Context, tx *sql.Tx, m *model.StockMovement) error {
+	m.CreatedAt = time.Now().UTC()
+
+	query := "INSERT INTO stock_movements (product_id, type, delta, balance, reference, note, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
+	err := tx.QueryRowContext(ctx, query, m.ProductID, m.Type, m.Delta, m.Balance, m.Reference, m.Note, m.CreatedAt).Scan(&m.ID)
+	if err != nil {
+		fmt.Println("repository.stock.recordStockMovement() Insert Error: ", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fendi/modul-03-task/database"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fmt"
+)
+
+type storeRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+func NewStoreRepository(db *sql.DB, dialect database.Dialect) StoreRepository {
+	return &storeRepository{db: db, dialect: dialect}
+}
+
+func (r *storeRepository) GetAllStore(ctx context.Context) ([]model.Store, error) {
+	query := "SELECT id, uuid, name, address, created_at FROM stores ORDER BY id ASC"
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		fmt.Println("repository.store.GetAllStore() Query Error: ", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	stores := make([]model.Store, 0)
+	for rows.Next() {
+		var s model.Store
+		err = rows.Scan(&s.ID, &s.UUID, &s.Name, &s.Address, &s.CreatedAt)
+		if err != nil {
+			fmt.Println("repository.store.GetAllStore() Scan Error: ", err.Error())
+			return nil, err
+		}
+		stores = append(stores, s)
+	}
+
+	return stores, rows.Err()
+}
+
+func (r *storeRepository) GetStoreByUUID(ctx context.Context, uuid string) (*model.Store, error) {
+	if !helper.IsValidUUID(uuid) {
+		return nil, nil
+	}
+
+	query := "SELECT id, uuid, name, address, created_at FROM stores WHERE uuid = $1"
+
+	var s model.Store
+	err := r.db.QueryRowContext(ctx, query, uuid).Scan(&s.ID, &s.UUID, &s.Name, &s.Address, &s.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		fmt.Println("repository.store.GetStoreByUUID() Scan Error: ", err.Error())
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (r *storeRepository) CreateStore(ctx context.Context, s model.Store) error {
+	query := "INSERT INTO stores (uuid, name, address, created_at, updated_at) VALUES ($1, $2, $3, $4, $4)"
+	_, err := r.db.ExecContext(ctx, query, s.UUID, s.Name, s.Address, s.CreatedAt)
+	if err != nil {
+		fmt.Println("repository.store.CreateStore() Exec Error: ", err.Error())
+	}
+
+	return err
+}
+
+func (r *storeRepository) UpdateStore(ctx context.Context, s model.Store) error {
+	query := "UPDATE stores SET name = $1, address = $2, updated_at = " + r.dialect.Now() + " WHERE uuid = $3"
+	_, err := r.db.ExecContext(ctx, query, s.Name, s.Address, s.UUID)
+	if err != nil {
+		fmt.Println("repository.store.UpdateStore() Exec Error: ", err.Error())
+	}
+
+	return err
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fendi/modul-03-task/database"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fmt"
+	"time"
+)
+
+type userRepository struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+func NewUserRepository(db *sql.DB, dialect database.Dialect) UserRepository {
+	return &userRepository{db: db, dialect: dialect}
+}
+
+// userColumns are the users and stores columns scanned by scanUser.
+const userColumns = "u.id, u.uuid, u.name, u.role, u.created_at, s.id, s.uuid, s.name, s.address, s.created_at"
+
+// userStoreJoin joins the store of a user, aliased u, as s.
+const userStoreJoin = " LEFT JOIN stores s ON s.id = u.store_id"
+
+func scanUser(scan func(dest ...interface{}) error, extra ...interface{}) (model.User, error) {
+	var u model.User
+	var storeID sql.NullInt64
+	var storeUUID, storeName, storeAddress sql.NullString
+	var storeCreatedAt sql.NullTime
+	dest := []interface{}{&u.ID, &u.UUID, &u.Name, &u.Role, &u.CreatedAt, &storeID, &storeUUID, &storeName, &storeAddress, &storeCreatedAt}
+	err := scan(append(dest, extra...)...)
+	if err != nil {
+		return u, err
+	}
+
+	if storeID.Valid {
+		u.Store = &model.Store{
+			ID:        storeID.Int64,
+			UUID:      storeUUID.String,
+			Name:      storeName.String,
+			Address:   storeAddress.String,
+			CreatedAt: storeCreatedAt.Time,
+		}
+	}
+
+	return u, nil
+}
+
+// storeIDOf returns the ID of the store of u, or nil.
+func storeIDOf(u model.User) *int64 {
+	if u.Store == nil {
+		return nil
+	}
+
+	return &u.Store.ID
+}
+
+func (r *userRepository) GetAllUser(ctx context.Context) ([]model.User, error) {
+	query := "SELECT " + userColumns + " FROM users u" + userStoreJoin + `
+		WHERE u.deleted_at IS NULL
+		ORDER BY u.id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		fmt.Println("repository.user.GetAllUser() Query Error: ", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]model.User, 0)
+	for rows.Next() {
+		u, err := scanUser(rows.Scan)
+		if err != nil {
+			fmt.Println("repository.user.GetAllUser() Scan Error: ", err.Error())
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *userRepository) GetUserByUUID(ctx context.Context, uuid string) (*model.User, error) {
+	if !helper.IsValidUUID(uuid) {
+		return nil, nil
+	}
+
+	query := "SELECT " + userColumns + " FROM users u" + userStoreJoin + `
+		WHERE u.deleted_at IS NULL AND u.uuid = $1`
+
+	u, err := scanUser(r.db.QueryRowContext(ctx, query, uuid).Scan)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		fmt.Println("repository.user.GetUserByUUID() Scan Error: ", err.Error())
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+func (r *userRepository) CountUserByRole(ctx context.Context, role string) (int64, error) {
+	query := "SELECT COUNT(id) FROM users WHERE deleted_at IS NULL AND role = $1"
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, query, role).Scan(&count)
+	if err != nil {
+		fmt.Println("repository.user.CountUserByRole() Scan Error: ", err.Error())
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, u model.User) error {
+	query := "INSERT INTO users (uuid, name, role, store_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $5)"
+	_, err := r.db.ExecContext(ctx, query, u.UUID, u.Name, u.Role, storeIDOf(u), u.CreatedAt)
+	if err != nil {
+		fmt.Println("repository.user.CreateUser() Exec Error: ", err.Error())
+	}
+
+	return err
+}
+
+func (r *userRepository) UpdateUser(ctx context.Context, u model.User) error {
+	query := "UPDATE users SET name = $1, role = $2, store_id = $3, updated_at = " + r.dialect.Now() + " WHERE uuid = $4 AND deleted_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, u.Name, u.Role, storeIDOf(u), u.UUID)
+	if err != nil {
+		fmt.Println("repository.user.UpdateUser() Exec Error: ", err.Error())
+	}
+
+	return err
+}
+
+func (r *userRepository) DeleteUser(ctx context.Context, uuid string) error {
+	query := "UPDATE users SET deleted_at = " + r.dialect.Now() + " WHERE uuid = $1 AND deleted_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, uuid)
+	if err != nil {
+		fmt.Println("repository.user.DeleteUser() Exec Error: ", err.Error())
+	}
+
+	return err
+}
+
+// apiKeyColumns are the api_keys columns scanned by scanAPIKey.
+const apiKeyColumns = "k.id, k.uuid, k.user_id, k.name, k.prefix, k.key_hash, k.created_at, k.last_used_at, k.revoked_at"
+
+func scanAPIKey(scan func(dest ...interface{}) error) (model.APIKey, error) {
+	var k model.APIKey
+	err := scan(apiKeyDest(&k)...)
+
+	return k, err
+}
+
+// apiKeyDest returns the scan destinations of apiKeyColumns for k.
+func apiKeyDest(k *model.APIKey) []interface{} {
+	return []interface{}{&k.ID, &k.UUID, &k.UserID, &k.Name, &k.Prefix, &k.Hash, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt}
+}
+
+func (r *userRepository) GetAPIKeysByUser(ctx context.Context, userID int64) ([]model.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys k WHERE k.user_id = $1 ORDER BY k.id ASC"
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		fmt.Println("repository.user.GetAPIKeysByUser() Query Error: ", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]model.APIKey, 0)
+	for rows.Next() {
+		k, err := scanAPIKey(rows.Scan)
+		if err != nil {
+			fmt.Println("repository.user.GetAPIKeysByUser() Scan Error: ", err.Error())
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *userRepository) GetAPIKeyByUUID(ctx context.Context, uuid string) (*model.APIKey, error) {
+	if !helper.IsValidUUID(uuid) {
+		return nil, nil
+	}
+
+	query := "SELECT " + apiKeyColumns + ` FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.uuid = $1 AND u.deleted_at IS NULL`
+
+	k, err := scanAPIKey(r.db.QueryRowContext(ctx, query, uuid).Scan)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		fmt.Println("repository.user.GetAPIKeyByUUID() Scan Error: ", err.Error())
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+func (r *userRepository) CreateAPIKey(ctx context.Context, k model.APIKey) error {
+	query := "INSERT INTO api_keys (uuid, user_id, name, prefix, key_hash, created_at) VALUES ($1, $2, $3, $4, $5, $6)"
+	_, err := r.db.ExecContext(ctx, query, k.UUID, k.UserID, k.Name, k.Prefix, k.Hash, k.CreatedAt)
+	if err != nil {
+		fmt.Println("repository.user.CreateAPIKey() Exec Error: ", err.Error())
+	}
+
+	return err
+}
+
+func (r *userRepository) RevokeAPIKey(ctx context.Context, uuid string, at time.Time) error {
+	query := "UPDATE api_keys SET revoked_at = $1 WHERE uuid = $2 AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, at.UTC(), uuid)
+	if err != nil {
+		fmt.Println("repository.user.RevokeAPIKey() Exec Error: ", err.Error())
+	}
+
+	return err
+}
+
+func (r *userRepository) GetUserByAPIKeyHash(ctx context.Context, hash string) (*model.User, *model.APIKey, error) {
+	query := "SELECT " + userColumns + ", " + apiKeyColumns + `
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id` + userStoreJoin + `
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL AND u.deleted_at IS NULL`
+
+	var k model.APIKey
+	u, err := scanUser(r.db.QueryRowContext(ctx, query, hash).Scan, apiKeyDest(&k)...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, nil
+		}
+		fmt.Println("repository.user.GetUserByAPIKeyHash() Scan Error: ", err.Error())
+		return nil, nil, err
+	}
+
+	return &u, &k, nil
+}
+
+func (r *userRepository) TouchAPIKey(ctx context.Context, id int64, at time.Time) error {
+	query := "UPDATE api_keys SET last_used_at = $1 WHERE id = $2"
+	_, err := r.db.ExecContext(ctx, query, at.UTC(), id)
+	if err != nil {
+		fmt.Println("repository.user.TouchAPIKey() Exec Error: ", err.Error())
+	}
+
+	return err
+}
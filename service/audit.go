@@ -0,0 +1,191 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"fendi/modul-03-task/transport"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request, which audit
+// entries refer to.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored by WithRequestID, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// newAuditEntry builds the audit entry of a change made by the caller of ctx. before
+// and after are snapshots of the entity keyed by field name; only the fields whose
+// values differ end up in the entry. A nil after records a delete or purge, every
+// field going to null, and a nil before a restore, every field coming back.
+func newAuditEntry(ctx context.Context, action, entityType, entityID string, before, after map[string]any) (model.AuditEntry, error) {
+	entry := model.AuditEntry{
+		UUID:       helper.GenerateUUID(),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    make(map[string]model.AuditChange),
+		RequestID:  RequestIDFromContext(ctx),
+	}
+	if p, ok := PrincipalFromContext(ctx); ok {
+		entry.ActorID = p.User.UUID
+		entry.ActorName = p.User.Name
+		entry.ActorRole = p.User.Role
+	}
+
+	fields := slices.Collect(maps.Keys(before))
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+
+	for _, field := range fields {
+		prev, err := json.Marshal(before[field])
+		if err != nil {
+			return model.AuditEntry{}, err
+		}
+		next, err := json.Marshal(after[field])
+		if err != nil {
+			return model.AuditEntry{}, err
+		}
+		if !bytes.Equal(prev, next) {
+			entry.Changes[field] = model.AuditChange{Before: prev, After: next}
+		}
+	}
+
+	return entry, nil
+}
+
+// productSnapshot returns the audited fields of p. The category is given by UUID.
+func productSnapshot(p model.Product) map[string]any {
+	var categoryID *string
+	if p.Category != nil {
+		categoryID = &p.Category.UUID
+	}
+
+	return map[string]any{
+		"sku":         p.SKU,
+		"barcode":     p.Barcode,
+		"name":        p.Name,
+		"price":       p.Price,
+		"stock":       p.Stock,
+		"category_id": categoryID,
+	}
+}
+
+// categorySnapshot returns the audited fields of c.
+func categorySnapshot(c model.Category) map[string]any {
+	return map[string]any{
+		"name":        c.Name,
+		"description": c.Description,
+	}
+}
+
+type AuditService struct {
+	repo     repository.AuditRepository
+	location *time.Location
+}
+
+// NewAuditService creates an AuditService. Date filters are calendar days in location.
+func NewAuditService(repo repository.AuditRepository, location *time.Location) *AuditService {
+	return &AuditService{repo: repo, location: location}
+}
+
+// GetAuditEntries lists the audit trail of the current store one page at a time,
+// newest first.
+func (s *AuditService) GetAuditEntries(ctx context.Context, req transport.AuditListRequest) (transport.AuditListResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.AuditListResponse{}, err
+	}
+	filter, err := s.parseAuditFilter(req)
+	if err != nil {
+		return transport.AuditListResponse{}, err
+	}
+
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	entries, err := s.repo.GetAuditEntries(ctx, store.ID, filter)
+	if err != nil {
+		fmt.Print("s.repo.GetAuditEntries() Error: ", err.Error())
+		return transport.AuditListResponse{}, err
+	}
+
+	response := transport.AuditListResponse{Data: entries}
+	if len(entries) > limit {
+		response.Data = entries[:limit]
+		cursor := encodeCursor(response.Data[limit-1].ID)
+		response.NextCursor = &cursor
+	}
+
+	return response, nil
+}
+
+// parseAuditFilter validates the list query parameters.
+func (s *AuditService) parseAuditFilter(req transport.AuditListRequest) (repository.AuditFilter, error) {
+	filter := repository.AuditFilter{Limit: DefaultPageLimit}
+
+	switch req.Entity {
+	case "", model.AuditEntityProduct, model.AuditEntityCategory:
+		filter.EntityType = req.Entity
+	default:
+		return filter, repository.NewValidationError(ErrInvalidQuery, "entity", fmt.Sprintf("must be %s or %s", model.AuditEntityProduct, model.AuditEntityCategory))
+	}
+	if req.EntityID != "" {
+		if !helper.IsValidUUID(req.EntityID) {
+			return filter, repository.NewValidationError(ErrInvalidQuery, "entity_id", "must be a valid UUID")
+		}
+		filter.EntityID = req.EntityID
+	}
+	switch req.Action {
+	case "", model.AuditActionUpdate, model.AuditActionDelete, model.AuditActionRestore, model.AuditActionPurge:
+		filter.Action = req.Action
+	default:
+		return filter, repository.NewValidationError(ErrInvalidQuery, "action", fmt.Sprintf("must be one of %s, %s, %s, %s", model.AuditActionUpdate, model.AuditActionDelete, model.AuditActionRestore, model.AuditActionPurge))
+	}
+
+	if req.StartDate != "" {
+		start, err := time.ParseInLocation("2006-01-02", req.StartDate, s.location)
+		if err != nil {
+			return filter, repository.NewValidationError(ErrInvalidQuery, "start_date", "must be in YYYY-MM-DD format")
+		}
+		filter.From = &start
+	}
+	if req.EndDate != "" {
+		end, err := time.ParseInLocation("2006-01-02", req.EndDate, s.location)
+		if err != nil {
+			return filter, repository.NewValidationError(ErrInvalidQuery, "end_date", "must be in YYYY-MM-DD format")
+		}
+		// end_date is inclusive, the filter upper bound is exclusive.
+		end = end.AddDate(0, 0, 1)
+		filter.To = &end
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, repository.NewValidationError(ErrInvalidQuery, "start_date", "must not be after end_date")
+	}
+
+	beforeID, limit, err := parseCursorPage(req.Cursor, req.Limit)
+	if err != nil {
+		return filter, err
+	}
+	filter.BeforeID = beforeID
+	filter.Limit = limit
+
+	return filter, nil
+}
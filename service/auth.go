@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"fendi/modul-03-task/transport"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrUnauthenticated is returned when a request carries no API key or one that
+	// is unknown, revoked or belongs to a deleted user.
+	ErrUnauthenticated = errors.New("missing or invalid API key")
+	// ErrForbidden is returned when the caller's role may not use an endpoint.
+	ErrForbidden = errors.New("your role is not allowed to do this")
+)
+
+// APIKeyPrefix starts every issued API key, so leaked keys are easy to recognise.
+const APIKeyPrefix = "pos_"
+
+// apiKeyPrefixLength is how much of a key is kept in clear as its prefix.
+const apiKeyPrefixLength = len(APIKeyPrefix) + 8
+
+// apiKeyTouchInterval is how often the last use of a key is recorded at most, so
+// busy keys do not write on every request.
+const apiKeyTouchInterval = time.Minute
+
+// OwnerKeyUserID is the user ID the OWNER_API_KEY authenticates as.
+const OwnerKeyUserID = "00000000-0000-0000-0000-000000000000"
+
+// MinOwnerKeyLength is the shortest OWNER_API_KEY accepted.
+const MinOwnerKeyLength = 32
+
+// Principal is the authenticated caller of a request. APIKey is nil for the
+// OWNER_API_KEY.
+type Principal struct {
+	User   model.User
+	APIKey *model.APIKey
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the caller stored by WithPrincipal.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+type AuthService struct {
+	repo         repository.UserRepository
+	storeRepo    repository.StoreRepository
+	ownerKeyHash []byte
+}
+
+// NewAuthService creates an AuthService. A non-empty ownerKey authenticates as an
+// owner without a stored user, to set up the first users and recover access.
+func NewAuthService(repo repository.UserRepository, storeRepo repository.StoreRepository, ownerKey string) *AuthService {
+	s := &AuthService{repo: repo, storeRepo: storeRepo}
+	if ownerKey != "" {
+		sum := sha256.Sum256([]byte(ownerKey))
+		s.ownerKeyHash = sum[:]
+	}
+
+	return s
+}
+
+// Authenticate finds the caller holding key.
+func (s *AuthService) Authenticate(ctx context.Context, key string) (Principal, error) {
+	if key == "" {
+		return Principal{}, ErrUnauthenticated
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	if s.ownerKeyHash != nil && subtle.ConstantTimeCompare(sum[:], s.ownerKeyHash) == 1 {
+		return Principal{User: model.User{UUID: OwnerKeyUserID, Name: "Owner (OWNER_API_KEY)", Role: model.RoleOwner}}, nil
+	}
+
+	user, apiKey, err := s.repo.GetUserByAPIKeyHash(ctx, hex.EncodeToString(sum[:]))
+	if err != nil {
+		fmt.Print("s.repo.GetUserByAPIKeyHash() Error: ", err.Error())
+		return Principal{}, err
+	}
+	if user == nil {
+		return Principal{}, ErrUnauthenticated
+	}
+
+	now := time.Now().UTC()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval {
+		// A failed touch only loses usage information, so it does not fail the request.
+		err = s.repo.TouchAPIKey(ctx, apiKey.ID, now)
+		if err != nil {
+			fmt.Print("s.repo.TouchAPIKey() Error: ", err.Error())
+		}
+		apiKey.LastUsedAt = &now
+	}
+
+	return Principal{User: *user, APIKey: apiKey}, nil
+}
+
+// Authorize checks that p may do what role may do.
+func (s *AuthService) Authorize(p Principal, role string) error {
+	if !model.RoleAllows(p.User.Role, role) {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// ResolveStore picks the store a request of p works in. Managers and cashiers
+// always work in their own store and may not name another. Owners name one by its
+// UUID in storeUUID, which may be left empty while the deployment has a single
+// store. It returns nil when no store was picked.
+func (s *AuthService) ResolveStore(ctx context.Context, p Principal, storeUUID string) (*model.Store, error) {
+	if p.User.Role != model.RoleOwner {
+		if p.User.Store == nil || (storeUUID != "" && storeUUID != p.User.Store.UUID) {
+			return nil, ErrStoreForbidden
+		}
+		return p.User.Store, nil
+	}
+
+	if storeUUID != "" {
+		return getStore(ctx, s.storeRepo, storeUUID)
+	}
+
+	stores, err := s.storeRepo.GetAllStore(ctx)
+	if err != nil {
+		fmt.Print("s.storeRepo.GetAllStore() Error: ", err.Error())
+		return nil, err
+	}
+	if len(stores) == 1 {
+		return &stores[0], nil
+	}
+
+	return nil, nil
+}
+
+// generateAPIKey returns a new random API key with its prefix and hash.
+func generateAPIKey() (key, prefix, hash string, err error) {
+	secret := make([]byte, 32)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	sum := sha256.Sum256([]byte(key))
+
+	return key, key[:apiKeyPrefixLength], hex.EncodeToString(sum[:]), nil
+}
+
+// Me describes the caller p and the store its request works in.
+func (s *AuthService) Me(ctx context.Context, p Principal) transport.MeResponse {
+	response := transport.MeResponse{User: transformUser(p.User)}
+	if p.APIKey != nil {
+		key := transformAPIKey(*p.APIKey)
+		response.APIKey = &key
+	}
+	if store, ok := StoreFromContext(ctx); ok {
+		st := transformStore(store)
+		response.Store = &st
+	}
+
+	return response
+}
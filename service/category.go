@@ -10,38 +10,76 @@ import (
 )
 
 type CategoryService struct {
-	repo *repository.CategoryRepository
+	repo repository.CategoryRepository
 }
 
-func NewCategoryService(repo *repository.CategoryRepository) *CategoryService {
+func NewCategoryService(repo repository.CategoryRepository) *CategoryService {
 	return &CategoryService{repo: repo}
 }
 
-// GetAllCategory retrieves all categories with an optional keyword filter.
-func (s *CategoryService) GetAllCategory(ctx context.Context, keyword string) ([]transport.CategoryItemResponse, error) {
-	categories, err := s.repo.GetAllCategory(ctx, keyword)
+// GetAllCategory lists categories one page at a time, optionally filtered by a name
+// keyword and sorted by name or creation.
+func (s *CategoryService) GetAllCategory(ctx context.Context, req transport.CategoryListRequest) (transport.CategoryListResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.CategoryListResponse{}, err
+	}
+	lp, err := parseListPage(req.Sort, []string{repository.SortByCreated, repository.SortByName}, req.Page, req.Limit, req.Cursor)
+	if err != nil {
+		return transport.CategoryListResponse{}, err
+	}
+
+	filter := repository.CategoryFilter{
+		Keyword: req.Search,
+		Sort:    lp.Sort,
+		Desc:    lp.Desc,
+		After:   lp.After,
+		Offset:  lp.Offset,
+		Limit:   lp.Limit + 1,
+	}
+
+	categories, err := s.repo.GetAllCategory(ctx, store.ID, filter)
 	if err != nil {
 		fmt.Print("s.repo.GetAllCategory() Error: ", err.Error())
-		return nil, err
+		return transport.CategoryListResponse{}, err
 	}
-	if len(categories) == 0 {
-		return []transport.CategoryItemResponse{}, nil
+
+	total, err := s.repo.CountCategory(ctx, store.ID, filter)
+	if err != nil {
+		fmt.Print("s.repo.CountCategory() Error: ", err.Error())
+		return transport.CategoryListResponse{}, err
 	}
 
-	categoriesResponse := transformCategory(categories)
+	meta := listMeta(lp, total, len(categories), func(i int) string {
+		c := categories[i]
+		return encodeListCursor(lp.Sort, lp.Desc, repository.CategorySortKey(c, lp.Sort), c.ID)
+	})
+	if len(categories) > lp.Limit {
+		categories = categories[:lp.Limit]
+	}
 
-	return categoriesResponse, nil
+	response := transport.CategoryListResponse{Data: transformCategory(categories), Meta: meta}
+	if response.Data == nil {
+		response.Data = []transport.CategoryItemResponse{}
+	}
+
+	return response, nil
 }
 
 // GetCategoryByUUID retrieves a category by its UUID.
 func (s *CategoryService) GetCategoryByUUID(ctx context.Context, uuid string) (transport.CategoryItemResponse, error) {
-	category, err := s.repo.GetCategoryByUUID(ctx, uuid)
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.CategoryItemResponse{}, err
+	}
+
+	category, err := s.repo.GetCategoryByUUID(ctx, store.ID, uuid)
 	if err != nil {
 		fmt.Print("s.repo.GetCategoryByUUID() Error: ", err.Error())
 		return transport.CategoryItemResponse{}, err
 	}
 	if category == nil {
-		return transport.CategoryItemResponse{}, nil
+		return transport.CategoryItemResponse{}, repository.ErrCategoryNotFound
 	}
 
 	categoryResponse := transport.CategoryItemResponse{
@@ -61,6 +99,7 @@ func transformCategory(c []model.Category) []transport.CategoryItemResponse {
 			ID:          category.UUID,
 			Name:        category.Name,
 			Description: category.Description,
+			DeletedAt:   category.DeletedAt,
 		}
 		categoriesResponse = append(categoriesResponse, categoryResponse)
 	}
@@ -70,6 +109,11 @@ func transformCategory(c []model.Category) []transport.CategoryItemResponse {
 
 // CreateCategory creates a new category.
 func (s *CategoryService) CreateCategory(ctx context.Context, req transport.CategoryRequest) (transport.CategoryItemResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.CategoryItemResponse{}, err
+	}
+
 	randomUUID := helper.GenerateUUID()
 
 	newCategory := model.Category{
@@ -78,7 +122,7 @@ func (s *CategoryService) CreateCategory(ctx context.Context, req transport.Cate
 		Description: &req.Description,
 	}
 
-	err := s.repo.CreateCategory(ctx, newCategory)
+	err = s.repo.CreateCategory(ctx, store.ID, newCategory)
 	if err != nil {
 		fmt.Print("s.repo.CreateCategory() Error: ", err.Error())
 		return transport.CategoryItemResponse{}, err
@@ -95,14 +139,19 @@ func (s *CategoryService) CreateCategory(ctx context.Context, req transport.Cate
 
 // UpdateCategory updates an existing category.
 func (s *CategoryService) UpdateCategory(ctx context.Context, id string, req transport.CategoryRequest) (transport.CategoryItemResponse, error) {
-	category, err := s.repo.GetCategoryByUUID(ctx, id)
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.CategoryItemResponse{}, err
+	}
+
+	category, err := s.repo.GetCategoryByUUID(ctx, store.ID, id)
 	if err != nil {
 		fmt.Print("s.repo.GetCategoryByUUID() Error: ", err.Error())
 		return transport.CategoryItemResponse{}, err
 	}
 	if category == nil {
 		fmt.Print("s.repo.GetCategoryByUUID() Error: category not found")
-		return transport.CategoryItemResponse{}, fmt.Errorf("category not found")
+		return transport.CategoryItemResponse{}, repository.ErrCategoryNotFound
 	}
 
 	newCategory := model.Category{
@@ -111,7 +160,12 @@ func (s *CategoryService) UpdateCategory(ctx context.Context, id string, req tra
 		Description: &req.Description,
 	}
 
-	err = s.repo.UpdateCategory(ctx, newCategory)
+	entry, err := newAuditEntry(ctx, model.AuditActionUpdate, model.AuditEntityCategory, id, categorySnapshot(*category), categorySnapshot(newCategory))
+	if err != nil {
+		return transport.CategoryItemResponse{}, err
+	}
+
+	err = s.repo.UpdateCategory(ctx, store.ID, newCategory, entry)
 	if err != nil {
 		fmt.Print("s.repo.UpdateCategory() Error: ", err.Error())
 		return transport.CategoryItemResponse{}, err
@@ -126,9 +180,28 @@ func (s *CategoryService) UpdateCategory(ctx context.Context, id string, req tra
 	return categoryResponse, nil
 }
 
-// DeleteCategory deletes a category by its UUID.
+// DeleteCategory moves a category to the trash.
 func (s *CategoryService) DeleteCategory(ctx context.Context, id string) error {
-	err := s.repo.DeleteCategory(ctx, id)
+	store, err := currentStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	category, err := s.repo.GetCategoryByUUID(ctx, store.ID, id)
+	if err != nil {
+		fmt.Print("s.repo.GetCategoryByUUID() Error: ", err.Error())
+		return err
+	}
+	if category == nil {
+		return repository.ErrCategoryNotFound
+	}
+
+	entry, err := newAuditEntry(ctx, model.AuditActionDelete, model.AuditEntityCategory, id, categorySnapshot(*category), nil)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.DeleteCategory(ctx, store.ID, id, entry)
 	if err != nil {
 		fmt.Print("s.repo.DeleteCategory() Error: ", err.Error())
 		return err
@@ -136,3 +209,71 @@ func (s *CategoryService) DeleteCategory(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// GetDeletedCategories lists the categories in the trash one page at a time, newest
+// first.
+func (s *CategoryService) GetDeletedCategories(ctx context.Context, req transport.TrashListRequest) (transport.CategoryTrashResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.CategoryTrashResponse{}, err
+	}
+	beforeID, limit, err := parseCursorPage(req.Cursor, req.Limit)
+	if err != nil {
+		return transport.CategoryTrashResponse{}, err
+	}
+
+	categories, err := s.repo.GetDeletedCategories(ctx, store.ID, repository.TrashFilter{BeforeID: beforeID, Limit: limit + 1})
+	if err != nil {
+		fmt.Print("s.repo.GetDeletedCategories() Error: ", err.Error())
+		return transport.CategoryTrashResponse{}, err
+	}
+
+	var response transport.CategoryTrashResponse
+	if len(categories) > limit {
+		categories = categories[:limit]
+		cursor := encodeCursor(categories[limit-1].ID)
+		response.NextCursor = &cursor
+	}
+	response.Data = transformCategory(categories)
+	if response.Data == nil {
+		response.Data = []transport.CategoryItemResponse{}
+	}
+
+	return response, nil
+}
+
+// RestoreCategory takes a category out of the trash.
+func (s *CategoryService) RestoreCategory(ctx context.Context, id string) (transport.CategoryItemResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.CategoryItemResponse{}, err
+	}
+
+	category, err := s.repo.GetDeletedCategoryByUUID(ctx, store.ID, id)
+	if err != nil {
+		fmt.Print("s.repo.GetDeletedCategoryByUUID() Error: ", err.Error())
+		return transport.CategoryItemResponse{}, err
+	}
+	if category == nil {
+		return transport.CategoryItemResponse{}, repository.ErrCategoryNotFound
+	}
+
+	entry, err := newAuditEntry(ctx, model.AuditActionRestore, model.AuditEntityCategory, id, nil, categorySnapshot(*category))
+	if err != nil {
+		return transport.CategoryItemResponse{}, err
+	}
+
+	err = s.repo.RestoreCategory(ctx, store.ID, id, entry)
+	if err != nil {
+		fmt.Print("s.repo.RestoreCategory() Error: ", err.Error())
+		return transport.CategoryItemResponse{}, err
+	}
+
+	categoryResponse := transport.CategoryItemResponse{
+		ID:          category.UUID,
+		Name:        category.Name,
+		Description: category.Description,
+	}
+
+	return categoryResponse, nil
+}
@@ -2,21 +2,41 @@ package service
 
 import (
 	"context"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
 	"fendi/modul-03-task/repository"
 	"fendi/modul-03-task/transport"
+	"fmt"
+	"strings"
+	"time"
 )
 
 type CheckoutService struct {
-	repo        *repository.CheckoutRepository
-	productRepo *repository.ProductRepository
+	repo        repository.CheckoutRepository
+	productRepo repository.ProductRepository
+	strictStock bool
+	location    *time.Location
 }
 
-func NewCheckoutService(repo *repository.CheckoutRepository, productRepo *repository.ProductRepository) *CheckoutService {
-	return &CheckoutService{repo: repo, productRepo: productRepo}
+// NewCheckoutService creates a CheckoutService. With strictStock enabled a checkout is
+// rejected as a whole when any item is missing or short on stock, instead of skipping
+// or reducing those items. Exported transactions show their times in location, the
+// store's time zone.
+func NewCheckoutService(repo repository.CheckoutRepository, productRepo repository.ProductRepository, strictStock bool, location *time.Location) *CheckoutService {
+	return &CheckoutService{repo: repo, productRepo: productRepo, strictStock: strictStock, location: location}
 }
 
 func (s *CheckoutService) CreateCheckout(ctx context.Context, req transport.CheckoutRequest) (transport.CheckoutResponse, error) {
-	transaction, err := s.repo.CreateCheckoutTransaction(ctx, req)
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.CheckoutResponse{}, err
+	}
+	err = validateCheckoutItems(req.Items)
+	if err != nil {
+		return transport.CheckoutResponse{}, err
+	}
+
+	transaction, err := s.repo.CreateCheckoutTransaction(ctx, store.ID, req, s.strictStock)
 	if err != nil {
 		return transport.CheckoutResponse{}, err
 	}
@@ -42,3 +62,173 @@ func (s *CheckoutService) CreateCheckout(ctx context.Context, req transport.Chec
 
 	return checkout, nil
 }
+
+// validateCheckoutItems checks that every item references its product by exactly one
+// of id, sku or barcode.
+func validateCheckoutItems(items []transport.CheckoutItem) error {
+	var fields []repository.FieldError
+	for i, item := range items {
+		refs := 0
+		for _, ref := range []string{item.ID, item.SKU, item.Barcode} {
+			if ref != "" {
+				refs++
+			}
+		}
+		if refs != 1 {
+			fields = append(fields, repository.FieldError{
+				Field:   fmt.Sprintf("items[%d]", i),
+				Message: "must reference the product by exactly one of id, sku or barcode",
+			})
+		}
+	}
+	if len(fields) > 0 {
+		return &repository.ValidationError{Err: repository.ErrInvalidRequest, Fields: fields}
+	}
+
+	return nil
+}
+
+// GetAllTransaction lists transactions newest first, filtered by date range, amount
+// range and product, one cursor page at a time.
+func (s *CheckoutService) GetAllTransaction(ctx context.Context, req transport.TransactionListRequest) (transport.TransactionListResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.TransactionListResponse{}, err
+	}
+	filter, err := s.parseTransactionFilter(req)
+	if err != nil {
+		return transport.TransactionListResponse{}, err
+	}
+
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	transactions, err := s.repo.GetAllTransaction(ctx, store.ID, filter)
+	if err != nil {
+		fmt.Print("s.repo.GetAllTransaction() Error: ", err.Error())
+		return transport.TransactionListResponse{}, err
+	}
+
+	response := transport.TransactionListResponse{Data: transactions}
+	if len(transactions) > limit {
+		response.Data = transactions[:limit]
+		cursor := encodeCursor(response.Data[limit-1].ID)
+		response.NextCursor = &cursor
+	}
+
+	return response, nil
+}
+
+// GetTransactionByUUID retrieves a transaction with its detail lines by its UUID.
+func (s *CheckoutService) GetTransactionByUUID(ctx context.Context, uuid string) (*model.Transaction, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.repo.GetTransactionByUUID(ctx, store.ID, uuid)
+	if err != nil {
+		fmt.Print("s.repo.GetTransactionByUUID() Error: ", err.Error())
+		return nil, err
+	}
+	if transaction == nil {
+		return nil, repository.ErrTransactionNotFound
+	}
+
+	return transaction, nil
+}
+
+// parseTransactionFilter validates the list query parameters. Dates are days in tz,
+// the store time zone by default, like in reports, and become a half-open UTC range.
+func (s *CheckoutService) parseTransactionFilter(req transport.TransactionListRequest) (repository.TransactionFilter, error) {
+	filter := repository.TransactionFilter{Limit: DefaultPageLimit}
+
+	location, err := loadLocation(req.TZ, s.location)
+	if err != nil {
+		return filter, err
+	}
+	if req.StartDate != "" {
+		start, err := parseDay("start_date", req.StartDate, location)
+		if err != nil {
+			return filter, err
+		}
+		start = start.UTC()
+		filter.From = &start
+	}
+	if req.EndDate != "" {
+		end, err := parseDay("end_date", req.EndDate, location)
+		if err != nil {
+			return filter, err
+		}
+		// end_date is inclusive, the filter upper bound is exclusive.
+		end = end.AddDate(0, 0, 1).UTC()
+		filter.To = &end
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, repository.NewValidationError(ErrInvalidQuery, "start_date", "must not be after end_date")
+	}
+
+	if req.MinAmount != "" {
+		minAmount, err := model.ParseMoney(req.MinAmount)
+		if err != nil || minAmount < 0 {
+			return filter, repository.NewValidationError(ErrInvalidQuery, "min_amount", "must be a non-negative number")
+		}
+		filter.MinAmount = &minAmount
+	}
+	if req.MaxAmount != "" {
+		maxAmount, err := model.ParseMoney(req.MaxAmount)
+		if err != nil || maxAmount < 0 {
+			return filter, repository.NewValidationError(ErrInvalidQuery, "max_amount", "must be a non-negative number")
+		}
+		filter.MaxAmount = &maxAmount
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return filter, repository.NewValidationError(ErrInvalidQuery, "min_amount", "must not be greater than max_amount")
+	}
+
+	if req.ProductID != "" {
+		if !helper.IsValidUUID(req.ProductID) {
+			return filter, repository.NewValidationError(ErrInvalidQuery, "product_id", "must be a valid UUID")
+		}
+		filter.ProductUUID = req.ProductID
+	}
+
+	beforeID, limit, err := parseCursorPage(req.Cursor, req.Limit)
+	if err != nil {
+		return filter, err
+	}
+	filter.BeforeID = beforeID
+	filter.Limit = limit
+
+	return filter, nil
+}
+
+// VoidTransaction reverses everything still refundable on a transaction and restores stock.
+func (s *CheckoutService) VoidTransaction(ctx context.Context, uuid string, req transport.RefundRequest) (*model.Refund, error) {
+	return s.createRefund(ctx, uuid, model.RefundTypeVoid, req)
+}
+
+// RefundTransaction reverses the requested items of a transaction and restores stock.
+func (s *CheckoutService) RefundTransaction(ctx context.Context, uuid string, req transport.RefundRequest) (*model.Refund, error) {
+	return s.createRefund(ctx, uuid, model.RefundTypeRefund, req)
+}
+
+func (s *CheckoutService) createRefund(ctx context.Context, uuid string, refundType string, req transport.RefundRequest) (*model.Refund, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return nil, repository.NewValidationError(repository.ErrInvalidRefund, "reason", "is required")
+	}
+
+	refund, err := s.repo.CreateRefund(ctx, store.ID, uuid, refundType, req)
+	if err != nil {
+		fmt.Print("s.repo.CreateRefund() Error: ", err.Error())
+		return nil, err
+	}
+
+	return refund, nil
+}
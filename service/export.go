@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"fendi/modul-03-task/export"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"fendi/modul-03-task/transport"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportBatchSize is how many transactions an export reads from the repository at a time.
+const exportBatchSize = 500
+
+// parseExportFormat validates the format of an export against the formats it offers.
+func parseExportFormat(format string, formats ...string) (string, error) {
+	if !slices.Contains(formats, format) {
+		return "", repository.NewValidationError(ErrInvalidQuery, "format", "must be one of json, "+strings.Join(formats, ", "))
+	}
+
+	return format, nil
+}
+
+// ExportReport exports the report of the days from req.StartDate to req.EndDate with
+// its category breakdown: a CSV file or XLSX workbook of tables, or a printable PDF
+// summary under the store's name and address.
+func (s *ReportService) ExportReport(ctx context.Context, req transport.ReportRequest, format string) (transport.ExportResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.ExportResponse{}, err
+	}
+	format, err = parseExportFormat(format, export.FormatCSV, export.FormatXLSX, export.FormatPDF)
+	if err != nil {
+		return transport.ExportResponse{}, err
+	}
+
+	rr, err := s.resolveRange(req.StartDate, req.EndDate, req.TZ)
+	if err != nil {
+		return transport.ExportResponse{}, err
+	}
+	top, err := parseReportTop(req.Top)
+	if err != nil {
+		return transport.ExportResponse{}, err
+	}
+
+	report, err := s.report(ctx, store.ID, rr, top)
+	if err != nil {
+		return transport.ExportResponse{}, err
+	}
+	categories, err := s.categoryReport(ctx, store.ID, rr)
+	if err != nil {
+		return transport.ExportResponse{}, err
+	}
+
+	response := transport.ExportResponse{
+		ContentType: export.ContentType(format),
+		Filename:    fmt.Sprintf("sales-report_%s_%s.%s", rr.StartDate, rr.EndDate, format),
+	}
+	printedAt := time.Now().In(rr.Location)
+	if format == export.FormatPDF {
+		response.WriteTo = func(w io.Writer) error {
+			_, err := reportDocument(store, report, categories, printedAt).WriteTo(w)
+			return err
+		}
+		return response, nil
+	}
+
+	response.WriteTo = func(w io.Writer) error {
+		ew, err := export.NewWriter(w, format, rr.Location)
+		if err != nil {
+			return err
+		}
+		err = writeReportTables(ew, store, report, categories)
+		if err != nil {
+			return err
+		}
+		return ew.Close()
+	}
+
+	return response, nil
+}
+
+// writeReportTables writes a report of store as four tables: the summary, both best
+// seller lists and the category breakdown.
+func writeReportTables(ew export.Writer, store model.Store, report transport.ReportResponse, categories transport.CategoryReportResponse) error {
+	summary := [][]any{
+		{"Store", store.Name},
+		{"Address", store.Address},
+		{"Start date", report.Range.StartDate},
+		{"End date", report.Range.EndDate},
+		{"Time zone", report.Range.Timezone},
+		{"Total revenue", report.TotalRevenue},
+		{"Transactions", report.TotalTransaction},
+		{"Items sold", report.ItemsSold},
+		{"Average basket", report.AverageBasket},
+	}
+	err := ew.Table("Summary", "Item", "Value")
+	if err != nil {
+		return err
+	}
+	for _, row := range summary {
+		err = ew.Row(row...)
+		if err != nil {
+			return err
+		}
+	}
+
+	lists := []struct {
+		name     string
+		products []transport.ProductSalesResponse
+	}{
+		{"Top by quantity", report.TopByQuantity},
+		{"Top by revenue", report.TopByRevenue},
+	}
+	for _, list := range lists {
+		err = ew.Table(list.name, "Rank", "Product ID", "Product", "Quantity", "Revenue")
+		if err != nil {
+			return err
+		}
+		for i, p := range list.products {
+			err = ew.Row(i+1, p.ProductID, p.ProductName, p.Quantity, p.Revenue)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = ew.Table("Categories", "Category ID", "Category", "Deleted", "Items sold", "Transactions", "Revenue", "Share (%)")
+	if err != nil {
+		return err
+	}
+	for _, c := range categories.Data {
+		var id, deleted string
+		if c.ID != nil {
+			id = *c.ID
+		}
+		if c.Deleted {
+			deleted = "yes"
+		}
+		err = ew.Row(id, c.Name, deleted, c.ItemsSold, c.TotalTransaction, c.TotalRevenue, c.Share)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reportDocument lays out the printable summary of a report of store.
+func reportDocument(store model.Store, report transport.ReportResponse, categories transport.CategoryReportResponse, printedAt time.Time) *export.Document {
+	doc := export.NewDocument()
+	doc.Footer = "Printed " + printedAt.Format("2006-01-02 15:04 MST")
+
+	if store.Name != "" {
+		doc.Title(store.Name)
+	}
+	if store.Address != "" {
+		doc.Text(store.Address)
+	}
+	if store.Name != "" || store.Address != "" {
+		doc.Rule()
+	}
+	doc.Title("Sales Report")
+	doc.Text(fmt.Sprintf("Period %s to %s (%s)", report.Range.StartDate, report.Range.EndDate, report.Range.Timezone))
+	doc.Space()
+
+	doc.Table([]export.Column{{Title: "Summary", Width: 24}, {Width: 24, Right: true}}, [][]string{
+		{"Total revenue", report.TotalRevenue.Rupiah()},
+		{"Transactions", strconv.FormatInt(report.TotalTransaction, 10)},
+		{"Items sold", strconv.FormatInt(report.ItemsSold, 10)},
+		{"Average basket", report.AverageBasket.Rupiah()},
+	})
+
+	lists := []struct {
+		name     string
+		products []transport.ProductSalesResponse
+	}{
+		{"Best sellers by quantity", report.TopByQuantity},
+		{"Best sellers by revenue", report.TopByRevenue},
+	}
+	for _, list := range lists {
+		doc.Space()
+		doc.Heading(list.name)
+		if len(list.products) == 0 {
+			doc.Text("No sales in this period.")
+			continue
+		}
+		rows := make([][]string, 0, len(list.products))
+		for i, p := range list.products {
+			rows = append(rows, []string{strconv.Itoa(i + 1), p.ProductName, strconv.FormatInt(p.Quantity, 10), p.Revenue.Rupiah()})
+		}
+		doc.Table([]export.Column{
+			{Title: "#", Width: 3, Right: true},
+			{Title: "Product", Width: 44},
+			{Title: "Qty", Width: 8, Right: true},
+			{Title: "Revenue", Width: 20, Right: true},
+		}, rows)
+	}
+
+	doc.Space()
+	doc.Heading("Sales by category")
+	if len(categories.Data) == 0 {
+		doc.Text("No sales in this period.")
+		return doc
+	}
+	rows := make([][]string, 0, len(categories.Data))
+	for _, c := range categories.Data {
+		name := c.Name
+		if c.Deleted {
+			name += " (deleted)"
+		}
+		rows = append(rows, []string{
+			name,
+			strconv.FormatInt(c.ItemsSold, 10),
+			strconv.FormatInt(c.TotalTransaction, 10),
+			c.TotalRevenue.Rupiah(),
+			strconv.FormatFloat(c.Share, 'f', 2, 64) + "%",
+		})
+	}
+	doc.Table([]export.Column{
+		{Title: "Category", Width: 30},
+		{Title: "Items", Width: 8, Right: true},
+		{Title: "Trx", Width: 6, Right: true},
+		{Title: "Revenue", Width: 20, Right: true},
+		{Title: "Share", Width: 8, Right: true},
+	}, rows)
+
+	return doc
+}
+
+// ExportTransactions exports every transaction matching the list filters as CSV or
+// XLSX, one row per detail line, newest first. The page parameters do not apply.
+// Transactions are read in batches while the file streams.
+func (s *CheckoutService) ExportTransactions(ctx context.Context, req transport.TransactionListRequest, format string) (transport.ExportResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.ExportResponse{}, err
+	}
+	format, err = parseExportFormat(format, export.FormatCSV, export.FormatXLSX)
+	if err != nil {
+		return transport.ExportResponse{}, err
+	}
+
+	req.Cursor, req.Limit = "", ""
+	filter, err := s.parseTransactionFilter(req)
+	if err != nil {
+		return transport.ExportResponse{}, err
+	}
+	filter.Limit = exportBatchSize
+
+	// The first batch is read up front so a failing repository is still reported
+	// as an error response.
+	batch, err := s.repo.GetAllTransaction(ctx, store.ID, filter)
+	if err != nil {
+		fmt.Print("s.repo.GetAllTransaction() Error: ", err.Error())
+		return transport.ExportResponse{}, err
+	}
+
+	writeTo := func(w io.Writer) error {
+		ew, err := export.NewWriter(w, format, s.location)
+		if err != nil {
+			return err
+		}
+		err = ew.Table("Transactions", "Transaction ID", fmt.Sprintf("Purchased at (%s)", s.location), "Transaction total",
+			"Product ID", "Product", "Price", "Quantity", "Refunded quantity", "Subtotal")
+		if err != nil {
+			return err
+		}
+
+		for {
+			for _, t := range batch {
+				err = writeTransactionRows(ew, t)
+				if err != nil {
+					return err
+				}
+			}
+			if len(batch) < exportBatchSize {
+				break
+			}
+
+			filter.BeforeID = batch[len(batch)-1].ID
+			batch, err = s.repo.GetAllTransaction(ctx, store.ID, filter)
+			if err != nil {
+				fmt.Print("s.repo.GetAllTransaction() Error: ", err.Error())
+				return err
+			}
+		}
+
+		return ew.Close()
+	}
+
+	return transport.ExportResponse{
+		ContentType: export.ContentType(format),
+		Filename:    fmt.Sprintf("transactions_%s.%s", time.Now().In(s.location).Format(reportDateLayout), format),
+		WriteTo:     writeTo,
+	}, nil
+}
+
+// writeTransactionRows writes a row for each detail line of t.
+func writeTransactionRows(ew export.Writer, t model.Transaction) error {
+	if len(t.Details) == 0 {
+		return ew.Row(t.UUID, t.PurchasedAt, t.TotalAmount)
+	}
+
+	for _, d := range t.Details {
+		err := ew.Row(t.UUID, t.PurchasedAt, t.TotalAmount, d.ProductUUID, d.ProductName, d.Price, d.Quantity, d.RefundedQuantity, d.SubTotal)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
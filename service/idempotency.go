@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"fmt"
+	"time"
+)
+
+// IdempotencyKeyTTL is how long a stored key is replayed before it may be reused.
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// MaxIdempotencyKeyLength is the longest Idempotency-Key accepted.
+const MaxIdempotencyKeyLength = 255
+
+var (
+	// ErrIdempotencyKeyReused is returned when a key is repeated with a different payload.
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request payload")
+	// ErrIdempotencyKeyInProgress is returned when the original request is still being processed.
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+	// ErrIdempotencyKeyInvalid is returned for empty or overlong keys.
+	ErrIdempotencyKeyInvalid = errors.New("invalid idempotency key")
+)
+
+type IdempotencyService struct {
+	repo repository.IdempotencyRepository
+}
+
+func NewIdempotencyService(repo repository.IdempotencyRepository) *IdempotencyService {
+	return &IdempotencyService{repo: repo}
+}
+
+// Fingerprint hashes the decoded request payload, so retries that only differ in
+// JSON formatting are still recognised as the same request.
+func Fingerprint(payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Begin reserves key for a request with the given fingerprint. It returns the stored
+// record when the original response should be replayed, or nil when the caller should
+// process the request and then call Complete or Abort.
+func (s *IdempotencyService) Begin(ctx context.Context, scope, key, fingerprint string) (*model.IdempotencyRecord, error) {
+	if key == "" || len(key) > MaxIdempotencyKeyLength {
+		return nil, ErrIdempotencyKeyInvalid
+	}
+
+	rec := model.IdempotencyRecord{
+		Scope:       scope,
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	existing, err := s.repo.ReserveIdempotencyKey(ctx, rec)
+	if err != nil {
+		fmt.Print("s.repo.ReserveIdempotencyKey() Error: ", err.Error())
+		return nil, err
+	}
+	if existing == nil {
+		return nil, nil
+	}
+
+	// Expired keys are released and reserved again for this request.
+	if time.Since(existing.CreatedAt) > IdempotencyKeyTTL {
+		err = s.repo.DeleteIdempotencyKey(ctx, scope, key)
+		if err != nil {
+			fmt.Print("s.repo.DeleteIdempotencyKey() Error: ", err.Error())
+			return nil, err
+		}
+
+		existing, err = s.repo.ReserveIdempotencyKey(ctx, rec)
+		if err != nil {
+			fmt.Print("s.repo.ReserveIdempotencyKey() Error: ", err.Error())
+			return nil, err
+		}
+		if existing == nil {
+			return nil, nil
+		}
+	}
+
+	if existing.Fingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if !existing.IsCompleted() {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	return existing, nil
+}
+
+// Complete stores the response of a request started with Begin so later retries replay it.
+func (s *IdempotencyService) Complete(ctx context.Context, scope, key string, statusCode int, contentType string, body []byte) error {
+	completedAt := time.Now().UTC()
+	responseBody := string(body)
+
+	err := s.repo.CompleteIdempotencyKey(ctx, model.IdempotencyRecord{
+		Scope:        scope,
+		Key:          key,
+		StatusCode:   &statusCode,
+		ContentType:  &contentType,
+		ResponseBody: &responseBody,
+		CompletedAt:  &completedAt,
+	})
+	if err != nil {
+		fmt.Print("s.repo.CompleteIdempotencyKey() Error: ", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Abort releases a key whose request failed unexpectedly, so the client can retry it.
+func (s *IdempotencyService) Abort(ctx context.Context, scope, key string) error {
+	err := s.repo.DeleteIdempotencyKey(ctx, scope, key)
+	if err != nil {
+		fmt.Print("s.repo.DeleteIdempotencyKey() Error: ", err.Error())
+		return err
+	}
+
+	return nil
+}
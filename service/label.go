@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fendi/modul-03-task/barcode"
+	"fendi/modul-03-task/label"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"fendi/modul-03-task/transport"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrNoLabels is returned when a label sheet would be empty.
+var ErrNoLabels = errors.New("no products to print labels for")
+
+// GetProductLabel renders the shelf label of a product.
+func (s *ProductService) GetProductLabel(ctx context.Context, uuid string, req transport.LabelRequest) (transport.LabelResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.LabelResponse{}, err
+	}
+	format, err := parseLabelFormat(req.Format)
+	if err != nil {
+		return transport.LabelResponse{}, err
+	}
+
+	product, err := s.repo.GetProductByUUID(ctx, store.ID, uuid)
+	if err != nil {
+		fmt.Print("s.repo.GetProductByUUID() Error: ", err.Error())
+		return transport.LabelResponse{}, err
+	}
+	if product == nil {
+		return transport.LabelResponse{}, repository.ErrProductNotFound
+	}
+
+	l, err := productLabel(*product)
+	if err != nil {
+		return transport.LabelResponse{}, err
+	}
+
+	body, err := label.Render(l, format)
+	if err != nil {
+		fmt.Print("label.Render() Error: ", err.Error())
+		return transport.LabelResponse{}, err
+	}
+
+	return transport.LabelResponse{ContentType: label.ContentType(format), Body: body, Page: 1, TotalPages: 1}, nil
+}
+
+// GetCategoryLabels renders one sheet of labels for the products of a category, in
+// name order. Each page holds label.SheetSize labels.
+func (s *ProductService) GetCategoryLabels(ctx context.Context, categoryUUID string, req transport.LabelRequest) (transport.LabelResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.LabelResponse{}, err
+	}
+	format, err := parseLabelFormat(req.Format)
+	if err != nil {
+		return transport.LabelResponse{}, err
+	}
+
+	lp, err := parseListPage(repository.SortByName, []string{repository.SortByName}, req.Page, strconv.Itoa(label.SheetSize), "")
+	if err != nil {
+		return transport.LabelResponse{}, err
+	}
+
+	category, err := s.categoryRepo.GetCategoryByUUID(ctx, store.ID, categoryUUID)
+	if err != nil {
+		fmt.Print("s.categoryRepo.GetCategoryByUUID() Error: ", err.Error())
+		return transport.LabelResponse{}, err
+	}
+	if category == nil {
+		return transport.LabelResponse{}, repository.ErrCategoryNotFound
+	}
+
+	filter := repository.ProductFilter{
+		CategoryUUID: categoryUUID,
+		Sort:         lp.Sort,
+		Offset:       lp.Offset,
+		Limit:        lp.Limit,
+	}
+	total, err := s.repo.CountProduct(ctx, store.ID, filter)
+	if err != nil {
+		fmt.Print("s.repo.CountProduct() Error: ", err.Error())
+		return transport.LabelResponse{}, err
+	}
+	if total == 0 {
+		return transport.LabelResponse{}, ErrNoLabels
+	}
+	totalPages := int((total + label.SheetSize - 1) / label.SheetSize)
+	if lp.Page > totalPages {
+		return transport.LabelResponse{}, repository.NewValidationError(ErrInvalidQuery, "page", fmt.Sprintf("must not be greater than %d", totalPages))
+	}
+
+	products, err := s.repo.GetAllProduct(ctx, store.ID, filter)
+	if err != nil {
+		fmt.Print("s.repo.GetAllProduct() Error: ", err.Error())
+		return transport.LabelResponse{}, err
+	}
+
+	labels := make([]label.Label, 0, len(products))
+	for _, p := range products {
+		l, err := productLabel(p)
+		if err != nil {
+			return transport.LabelResponse{}, err
+		}
+		labels = append(labels, l)
+	}
+
+	body, err := label.RenderSheet(labels, format)
+	if err != nil {
+		fmt.Print("label.RenderSheet() Error: ", err.Error())
+		return transport.LabelResponse{}, err
+	}
+
+	return transport.LabelResponse{ContentType: label.ContentType(format), Body: body, Page: lp.Page, TotalPages: totalPages}, nil
+}
+
+// parseLabelFormat validates the format query parameter, which defaults to SVG.
+func parseLabelFormat(format string) (string, error) {
+	if format == "" {
+		return label.FormatSVG, nil
+	}
+	if !label.ValidFormat(format) {
+		return "", repository.NewValidationError(ErrInvalidQuery, "format", fmt.Sprintf("must be %s or %s", label.FormatSVG, label.FormatPNG))
+	}
+
+	return format, nil
+}
+
+// productLabel builds the label of p. Products without a barcode are labelled with
+// their SKU as Code 128.
+func productLabel(p model.Product) (label.Label, error) {
+	code, symbology := p.SKU, barcode.Code128
+	if p.Barcode != nil {
+		code, symbology = *p.Barcode, barcode.Detect(*p.Barcode)
+	}
+
+	bc, err := barcode.EncodeAs(code, symbology)
+	if err != nil {
+		return label.Label{}, fmt.Errorf("product %s: %w", p.UUID, err)
+	}
+
+	return label.Label{Name: p.Name, Price: p.Price, Barcode: bc}, nil
+}
+
+// validateProductCodes checks that a product's codes can be printed on its label: the
+// barcode by the rules of its symbology and the SKU, its fallback, as Code 128.
+func validateProductCodes(sku string, code *string) error {
+	if sku != "" {
+		err := barcode.ValidateAs(sku, barcode.Code128)
+		if err != nil {
+			return repository.NewValidationError(repository.ErrInvalidRequest, "sku", barcodeMessage(err))
+		}
+	}
+	if code != nil {
+		err := barcode.Validate(*code)
+		if err != nil {
+			return repository.NewValidationError(repository.ErrInvalidRequest, "barcode", barcodeMessage(err))
+		}
+	}
+
+	return nil
+}
+
+// barcodeMessage strips the barcode.ErrInvalid prefix from a validation error.
+func barcodeMessage(err error) string {
+	return strings.TrimPrefix(err.Error(), barcode.ErrInvalid.Error()+": ")
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"fendi/modul-03-task/transport"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultPageLimit is the page size used when the client does not ask for one.
+	DefaultPageLimit = 20
+	// MaxPageLimit is the largest page size a client may ask for.
+	MaxPageLimit = 100
+)
+
+// ErrInvalidQuery is returned when list query parameters cannot be parsed.
+var ErrInvalidQuery = errors.New("invalid query parameter")
+
+// encodeCursor turns the ID of the last row on a page into an opaque cursor.
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+// decodeCursor reverses encodeCursor.
+func decodeCursor(cursor string) (int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || id < 1 {
+		return 0, errors.New("invalid cursor")
+	}
+
+	return id, nil
+}
+
+// parseCursorPage validates the cursor and limit query parameters of a listing that
+// pages by ID, newest first. It returns the ID to list below, zero for the first page,
+// and the page size.
+//
+// Listings fetch one row more than the page size to find out whether there is a
+// next page.
+func parseCursorPage(cursor, limit string) (int64, int, error) {
+	var beforeID int64
+	if cursor != "" {
+		var err error
+		beforeID, err = decodeCursor(cursor)
+		if err != nil {
+			return 0, 0, repository.NewValidationError(ErrInvalidQuery, "cursor", "is invalid")
+		}
+	}
+
+	n := DefaultPageLimit
+	if limit != "" {
+		var err error
+		n, err = strconv.Atoi(limit)
+		if err != nil || n < 1 || n > MaxPageLimit {
+			return 0, 0, repository.NewValidationError(ErrInvalidQuery, "limit", fmt.Sprintf("must be between 1 and %d", MaxPageLimit))
+		}
+	}
+
+	return beforeID, n, nil
+}
+
+// listPage is the parsed paging and sorting part of a listing query.
+type listPage struct {
+	Sort   string
+	Desc   bool
+	After  *repository.ListCursor
+	Page   int
+	Offset int
+	Limit  int
+}
+
+// parseListPage validates the sort, page, limit and cursor query parameters of a listing.
+// sortParam is one of sorts, optionally prefixed with "-" for descending order; it
+// defaults to creation order. A cursor takes the place of page.
+func parseListPage(sortParam string, sorts []string, page, limit, cursor string) (listPage, error) {
+	lp := listPage{Sort: repository.SortByCreated, Page: 1, Limit: DefaultPageLimit}
+
+	if sortParam != "" {
+		field, desc := strings.CutPrefix(sortParam, "-")
+		if !slices.Contains(sorts, field) {
+			return lp, repository.NewValidationError(ErrInvalidQuery, "sort", fmt.Sprintf("must be one of %s, optionally prefixed with -", strings.Join(sorts, ", ")))
+		}
+		lp.Sort = field
+		lp.Desc = desc
+	}
+
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > MaxPageLimit {
+			return lp, repository.NewValidationError(ErrInvalidQuery, "limit", fmt.Sprintf("must be between 1 and %d", MaxPageLimit))
+		}
+		lp.Limit = n
+	}
+
+	if cursor != "" {
+		if page != "" {
+			return lp, repository.NewValidationError(ErrInvalidQuery, "page", "cannot be combined with cursor")
+		}
+		after, err := decodeListCursor(lp.Sort, lp.Desc, cursor)
+		if err != nil {
+			return lp, repository.NewValidationError(ErrInvalidQuery, "cursor", "is invalid for this sort order")
+		}
+		lp.After = after
+		lp.Page = 0
+		return lp, nil
+	}
+
+	if page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			return lp, repository.NewValidationError(ErrInvalidQuery, "page", "must be a positive integer")
+		}
+		lp.Page = n
+	}
+	lp.Offset = (lp.Page - 1) * lp.Limit
+
+	return lp, nil
+}
+
+// listCursor is the encoded form of a repository.ListCursor. The sort order is kept so
+// a cursor cannot be replayed against a different one.
+type listCursor struct {
+	Sort  string      `json:"s"`
+	Desc  bool        `json:"d,omitempty"`
+	Value interface{} `json:"v,omitempty"`
+	ID    int64       `json:"id"`
+}
+
+// encodeListCursor turns the sort key and ID of the last row on a page into an opaque cursor.
+func encodeListCursor(sort string, desc bool, value interface{}, id int64) string {
+	data, _ := json.Marshal(listCursor{Sort: sort, Desc: desc, Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeListCursor reverses encodeListCursor, restoring the sort key to the type the
+// repositories compare it as.
+func decodeListCursor(sort string, desc bool, cursor string) (*repository.ListCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var c listCursor
+	err = json.Unmarshal(raw, &c)
+	if err != nil {
+		return nil, err
+	}
+	if c.Sort != sort || c.Desc != desc || c.ID < 1 {
+		return nil, errors.New("cursor does not match the sort order")
+	}
+
+	after := &repository.ListCursor{ID: c.ID}
+	switch sort {
+	case repository.SortByName:
+		v, ok := c.Value.(string)
+		if !ok {
+			return nil, errors.New("invalid cursor value")
+		}
+		after.Value = v
+	case repository.SortByPrice:
+		v, ok := c.Value.(float64)
+		if !ok {
+			return nil, errors.New("invalid cursor value")
+		}
+		price, err := model.MoneyFromFloat(v)
+		if err != nil {
+			return nil, err
+		}
+		after.Value = price
+	case repository.SortByStock:
+		v, ok := c.Value.(float64)
+		if !ok {
+			return nil, errors.New("invalid cursor value")
+		}
+		after.Value = int64(v)
+	}
+
+	return after, nil
+}
+
+// listMeta builds the listing metadata. rows is the page fetched with one extra row,
+// so its length tells whether there is a next page; cursorOf encodes the last row kept.
+func listMeta(lp listPage, total int64, rows int, cursorOf func(i int) string) transport.ListMeta {
+	meta := transport.ListMeta{Total: total, Limit: lp.Limit}
+	if lp.After == nil {
+		page := lp.Page
+		meta.Page = &page
+	}
+	if rows > lp.Limit {
+		cursor := cursorOf(lp.Limit - 1)
+		meta.NextCursor = &cursor
+	}
+
+	return meta
+}
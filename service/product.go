@@ -2,50 +2,154 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fendi/modul-03-task/helper"
 	"fendi/modul-03-task/model"
 	"fendi/modul-03-task/repository"
 	"fendi/modul-03-task/transport"
 	"fmt"
+	"strings"
+	"time"
 )
 
+var (
+	// ErrStockNotEditable is returned when a product update tries to change stock directly.
+	ErrStockNotEditable = errors.New("stock cannot be changed through a product update, use a stock adjustment instead")
+	// ErrSKUGenerationFailed is returned when every generated SKU tried for a new
+	// product was already taken.
+	ErrSKUGenerationFailed = errors.New("could not generate a free SKU")
+)
+
+// skuAttempts is how many generated SKUs are tried before giving up on a create.
+const skuAttempts = 10
+
 type ProductService struct {
-	repo         *repository.ProductRepository
-	categoryRepo *repository.CategoryRepository
+	repo         repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+	stockRepo    repository.StockRepository
+	skuGenerator *helper.SKUGenerator
 }
 
-func NewProductService(repo *repository.ProductRepository, categoryRepo *repository.CategoryRepository) *ProductService {
+// NewProductService creates a ProductService. skuGenerator names products created
+// without a SKU.
+func NewProductService(repo repository.ProductRepository, categoryRepo repository.CategoryRepository, stockRepo repository.StockRepository, skuGenerator *helper.SKUGenerator) *ProductService {
 	return &ProductService{
 		repo:         repo,
 		categoryRepo: categoryRepo,
+		stockRepo:    stockRepo,
+		skuGenerator: skuGenerator,
 	}
 }
 
-// GetAllProduct retrieves all products with an optional keyword filter.
-func (s *ProductService) GetAllProduct(ctx context.Context, keyword string) ([]transport.ProductItemResponse, error) {
-	products, err := s.repo.GetAllProduct(ctx, keyword)
+// GetAllProduct lists products one page at a time, optionally filtered by name keyword,
+// category, price range and stock status, and sorted by name, price, stock or creation.
+func (s *ProductService) GetAllProduct(ctx context.Context, req transport.ProductListRequest) (transport.ProductListResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.ProductListResponse{}, err
+	}
+	sorts := []string{repository.SortByCreated, repository.SortByName, repository.SortByPrice, repository.SortByStock}
+	lp, err := parseListPage(req.Sort, sorts, req.Page, req.Limit, req.Cursor)
+	if err != nil {
+		return transport.ProductListResponse{}, err
+	}
+
+	filter, err := parseProductFilter(req)
+	if err != nil {
+		return transport.ProductListResponse{}, err
+	}
+	filter.Sort = lp.Sort
+	filter.Desc = lp.Desc
+	filter.After = lp.After
+	filter.Offset = lp.Offset
+	filter.Limit = lp.Limit + 1
+
+	products, err := s.repo.GetAllProduct(ctx, store.ID, filter)
 	if err != nil {
 		fmt.Print("s.repo.GetAllProduct() Error: ", err.Error())
-		return nil, err
+		return transport.ProductListResponse{}, err
 	}
-	if len(products) == 0 {
-		return []transport.ProductItemResponse{}, nil
+
+	total, err := s.repo.CountProduct(ctx, store.ID, filter)
+	if err != nil {
+		fmt.Print("s.repo.CountProduct() Error: ", err.Error())
+		return transport.ProductListResponse{}, err
+	}
+
+	meta := listMeta(lp, total, len(products), func(i int) string {
+		p := products[i]
+		return encodeListCursor(lp.Sort, lp.Desc, repository.ProductSortKey(p, lp.Sort), p.ID)
+	})
+	if len(products) > lp.Limit {
+		products = products[:lp.Limit]
 	}
 
-	productsResponse := transformProduct(products)
+	response := transport.ProductListResponse{Data: transformProduct(products), Meta: meta}
+	if response.Data == nil {
+		response.Data = []transport.ProductItemResponse{}
+	}
 
-	return productsResponse, nil
+	return response, nil
+}
+
+// parseProductFilter validates the product list filter parameters.
+func parseProductFilter(req transport.ProductListRequest) (repository.ProductFilter, error) {
+	filter := repository.ProductFilter{
+		Keyword: req.Search,
+		SKU:     strings.TrimSpace(req.SKU),
+		Barcode: strings.TrimSpace(req.Barcode),
+	}
+
+	if req.CategoryID != "" {
+		if !helper.IsValidUUID(req.CategoryID) {
+			return filter, repository.NewValidationError(ErrInvalidQuery, "category_id", "must be a valid UUID")
+		}
+		filter.CategoryUUID = req.CategoryID
+	}
+
+	if req.MinPrice != "" {
+		minPrice, err := model.ParseMoney(req.MinPrice)
+		if err != nil || minPrice < 0 {
+			return filter, repository.NewValidationError(ErrInvalidQuery, "min_price", "must be a non-negative number")
+		}
+		filter.MinPrice = &minPrice
+	}
+	if req.MaxPrice != "" {
+		maxPrice, err := model.ParseMoney(req.MaxPrice)
+		if err != nil || maxPrice < 0 {
+			return filter, repository.NewValidationError(ErrInvalidQuery, "max_price", "must be a non-negative number")
+		}
+		filter.MaxPrice = &maxPrice
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return filter, repository.NewValidationError(ErrInvalidQuery, "min_price", "must not be greater than max_price")
+	}
+
+	switch req.Stock {
+	case "", repository.StockFilterInStock, repository.StockFilterOutOfStock, repository.StockFilterUnlimited:
+		filter.Stock = req.Stock
+	default:
+		return filter, repository.NewValidationError(ErrInvalidQuery, "stock", fmt.Sprintf("must be one of %s, %s, %s",
+			repository.StockFilterInStock, repository.StockFilterOutOfStock, repository.StockFilterUnlimited))
+	}
+
+	return filter, nil
 }
 
 // GetProductByUUID retrieves a product by its UUID.
 func (s *ProductService) GetProductByUUID(ctx context.Context, uuid string) (transport.ProductItemResponse, error) {
-	product, err := s.repo.GetProductByUUID(ctx, uuid)
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.ProductItemResponse{}, err
+	}
+
+	product, err := s.repo.GetProductByUUID(ctx, store.ID, uuid)
 	if err != nil {
 		fmt.Print("s.repo.GetProductByUUID() Error: ", err.Error())
 		return transport.ProductItemResponse{}, err
 	}
 	if product == nil {
-		return transport.ProductItemResponse{}, nil
+		return transport.ProductItemResponse{}, repository.ErrProductNotFound
 	}
 
 	var categoryResponse *transport.CategoryItemResponse
@@ -59,6 +163,8 @@ func (s *ProductService) GetProductByUUID(ctx context.Context, uuid string) (tra
 
 	productResponse := transport.ProductItemResponse{
 		ID:       product.UUID,
+		SKU:      product.SKU,
+		Barcode:  product.Barcode,
 		Name:     product.Name,
 		Stock:    product.Stock,
 		Price:    product.Price,
@@ -82,11 +188,14 @@ func transformProduct(p []model.Product) []transport.ProductItemResponse {
 		}
 
 		productResponse := transport.ProductItemResponse{
-			ID:       product.UUID,
-			Name:     product.Name,
-			Stock:    product.Stock,
-			Price:    product.Price,
-			Category: categoryResponse,
+			ID:        product.UUID,
+			SKU:       product.SKU,
+			Barcode:   product.Barcode,
+			Name:      product.Name,
+			Stock:     product.Stock,
+			Price:     product.Price,
+			Category:  categoryResponse,
+			DeletedAt: product.DeletedAt,
 		}
 		productsResponse = append(productsResponse, productResponse)
 	}
@@ -96,51 +205,76 @@ func transformProduct(p []model.Product) []transport.ProductItemResponse {
 
 // CreateProduct creates a new product.
 func (s *ProductService) CreateProduct(ctx context.Context, req transport.ProductRequest) (transport.ProductItemResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.ProductItemResponse{}, err
+	}
+
 	randUUID := helper.GenerateUUID()
-	randSKU := helper.GenerateSKU()
+
+	// Without a client SKU one is generated; an empty barcode means none.
+	var sku string
+	if req.SKU != nil {
+		sku = strings.TrimSpace(*req.SKU)
+	}
+	code := productBarcode(req.Barcode)
+	err = validateProductCodes(sku, code)
+	if err != nil {
+		return transport.ProductItemResponse{}, err
+	}
 
 	var categoryID *int64
+	var categoryName string
 	if req.CategoryID != "" {
 		// Fetch category to get the integer ID
-		category, err := s.categoryRepo.GetCategoryByUUID(ctx, req.CategoryID)
+		category, err := s.categoryRepo.GetCategoryByUUID(ctx, store.ID, req.CategoryID)
 		if err != nil {
 			fmt.Print("s.categoryRepo.GetCategoryByUUID() Error: ", err.Error())
 			return transport.ProductItemResponse{}, err
 		}
 		if category == nil {
 			fmt.Print("s.categoryRepo.GetCategoryByUUID() Error: category not found")
-			return transport.ProductItemResponse{}, fmt.Errorf("category not found")
+			return transport.ProductItemResponse{}, repository.NewValidationError(repository.ErrInvalidRequest, "category_id", "does not match an existing category")
 		}
 		if category.UUID != "" {
 			categoryID = &category.ID
+			categoryName = category.Name
 		}
 	}
 
 	newProduct := model.Product{
-		UUID:  randUUID,
-		SKU:   randSKU,
-		Name:  req.Name,
-		Stock: req.Stock,
-		Price: req.Price,
-		Category: &model.Category{
+		UUID:    randUUID,
+		SKU:     sku,
+		Barcode: code,
+		Name:    req.Name,
+		Stock:   req.Stock,
+		Price:   req.Price,
+	}
+
+	if categoryID != nil {
+		newProduct.Category = &model.Category{
 			ID: *categoryID,
-		},
+		}
 	}
 
-	err := s.repo.CreateProduct(ctx, newProduct)
+	if sku != "" {
+		err = s.repo.CreateProduct(ctx, store.ID, newProduct)
+	} else {
+		err = s.createWithGeneratedSKU(ctx, store.ID, newProduct, categoryName)
+	}
 	if err != nil {
 		fmt.Print("s.repo.CreateProduct() Error: ", err.Error())
 		return transport.ProductItemResponse{}, err
 	}
 
 	// Fetch the created product to get category info
-	createdProduct, err := s.repo.GetProductByUUID(ctx, randUUID)
+	createdProduct, err := s.repo.GetProductByUUID(ctx, store.ID, randUUID)
 	if err != nil {
 		fmt.Print("s.repo.GetProductByUUID() Error: ", err.Error())
 		return transport.ProductItemResponse{}, err
 	}
 	if createdProduct == nil {
-		return transport.ProductItemResponse{}, fmt.Errorf("created product not found")
+		return transport.ProductItemResponse{}, repository.ErrProductNotFound
 	}
 
 	var categoryResponse *transport.CategoryItemResponse
@@ -154,6 +288,8 @@ func (s *ProductService) CreateProduct(ctx context.Context, req transport.Produc
 
 	productResponse := transport.ProductItemResponse{
 		ID:       createdProduct.UUID,
+		SKU:      createdProduct.SKU,
+		Barcode:  createdProduct.Barcode,
 		Name:     createdProduct.Name,
 		Stock:    createdProduct.Stock,
 		Price:    createdProduct.Price,
@@ -163,62 +299,113 @@ func (s *ProductService) CreateProduct(ctx context.Context, req transport.Produc
 	return productResponse, nil
 }
 
+// createWithGeneratedSKU creates p under a generated SKU. Sequence numbers are
+// handed out by the repository, so concurrent creates never share one. A SKU that
+// is taken anyway, e.g. entered by hand, is retried with a new sequence number or
+// random block. The client never sees a duplicate_sku for a SKU it did not send.
+func (s *ProductService) createWithGeneratedSKU(ctx context.Context, storeID int64, p model.Product, categoryName string) error {
+	in := helper.SKUInput{Category: categoryName, Date: time.Now()}
+	for range skuAttempts {
+		if s.skuGenerator.UsesSequence() {
+			next, err := s.repo.NextSKUSequence(ctx, s.skuGenerator.SequencePrefix(in))
+			if err != nil {
+				fmt.Print("s.repo.NextSKUSequence() Error: ", err.Error())
+				return err
+			}
+			in.Sequence = next
+		}
+		p.SKU = s.skuGenerator.Generate(in)
+
+		err := s.repo.CreateProduct(ctx, storeID, p)
+		if !errors.Is(err, repository.ErrDuplicateSKU) {
+			return err
+		}
+	}
+
+	return ErrSKUGenerationFailed
+}
+
 // UpdateProduct updates an existing product.
 func (s *ProductService) UpdateProduct(ctx context.Context, id string, req transport.ProductRequest) (transport.ProductItemResponse, error) {
-	product, err := s.repo.GetProductByUUID(ctx, id)
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.ProductItemResponse{}, err
+	}
+
+	product, err := s.repo.GetProductByUUID(ctx, store.ID, id)
 	if err != nil {
 		fmt.Print("s.repo.GetProductByUUID() Error: ", err.Error())
 		return transport.ProductItemResponse{}, err
 	}
 	if product == nil {
 		fmt.Print("s.repo.GetProductByUUID() Error: product not found")
-		return transport.ProductItemResponse{}, fmt.Errorf("product not found")
+		return transport.ProductItemResponse{}, repository.ErrProductNotFound
+	}
+
+	// Sending the current stock back is allowed so clients can PUT the full product.
+	if req.Stock != nil && (product.Stock == nil || *req.Stock != *product.Stock) {
+		return transport.ProductItemResponse{}, ErrStockNotEditable
+	}
+
+	// Omitted codes stay as they are; an empty barcode removes it.
+	sku := product.SKU
+	if req.SKU != nil {
+		sku = strings.TrimSpace(*req.SKU)
+		if sku == "" {
+			return transport.ProductItemResponse{}, repository.NewValidationError(repository.ErrInvalidRequest, "sku", "must not be blank")
+		}
+	}
+	code := product.Barcode
+	if req.Barcode != nil {
+		code = productBarcode(req.Barcode)
+	}
+	err = validateProductCodes(sku, code)
+	if err != nil {
+		return transport.ProductItemResponse{}, err
+	}
+
+	newProduct := model.Product{
+		UUID:    id,
+		SKU:     sku,
+		Barcode: code,
+		Name:    req.Name,
+		Price:   req.Price,
+		// Stock is left untouched by UpdateProduct, it is only set for the audit entry.
+		Stock: product.Stock,
 	}
 
-	var categoryID *int64
 	if req.CategoryID != "" {
-		// Fetch category to get the integer ID
-		category, err := s.categoryRepo.GetCategoryByUUID(ctx, req.CategoryID)
+		category, err := s.categoryRepo.GetCategoryByUUID(ctx, store.ID, req.CategoryID)
 		if err != nil {
 			fmt.Print("s.categoryRepo.GetCategoryByUUID() Error: ", err.Error())
 			return transport.ProductItemResponse{}, err
 		}
 		if category == nil {
 			fmt.Print("s.categoryRepo.GetCategoryByUUID() Error: category not found")
-			return transport.ProductItemResponse{}, fmt.Errorf("category not found")
+			return transport.ProductItemResponse{}, repository.NewValidationError(repository.ErrInvalidRequest, "category_id", "does not match an existing category")
 		}
-		if category.UUID != "" {
-			categoryID = &category.ID
-		}
-	}
-
-	newProduct := model.Product{
-		UUID:  id,
-		Name:  req.Name,
-		Stock: req.Stock,
-		Price: req.Price,
+		newProduct.Category = category
 	}
 
-	if categoryID != nil {
-		newProduct.Category = &model.Category{
-			ID: *categoryID,
-		}
+	entry, err := newAuditEntry(ctx, model.AuditActionUpdate, model.AuditEntityProduct, id, productSnapshot(*product), productSnapshot(newProduct))
+	if err != nil {
+		return transport.ProductItemResponse{}, err
 	}
 
-	err = s.repo.UpdateProduct(ctx, newProduct)
+	err = s.repo.UpdateProduct(ctx, store.ID, newProduct, entry)
 	if err != nil {
 		fmt.Print("s.repo.UpdateProduct() Error: ", err.Error())
 		return transport.ProductItemResponse{}, err
 	}
 
 	// Fetch the updated product to get category info
-	updatedProduct, err := s.repo.GetProductByUUID(ctx, id)
+	updatedProduct, err := s.repo.GetProductByUUID(ctx, store.ID, id)
 	if err != nil {
 		fmt.Print("s.repo.GetProductByUUID() Error: ", err.Error())
 		return transport.ProductItemResponse{}, err
 	}
 	if updatedProduct == nil {
-		return transport.ProductItemResponse{}, fmt.Errorf("updated product not found")
+		return transport.ProductItemResponse{}, repository.ErrProductNotFound
 	}
 
 	var categoryResponse *transport.CategoryItemResponse
@@ -232,6 +419,8 @@ func (s *ProductService) UpdateProduct(ctx context.Context, id string, req trans
 
 	productResponse := transport.ProductItemResponse{
 		ID:       updatedProduct.UUID,
+		SKU:      updatedProduct.SKU,
+		Barcode:  updatedProduct.Barcode,
 		Name:     updatedProduct.Name,
 		Stock:    updatedProduct.Stock,
 		Price:    updatedProduct.Price,
@@ -241,9 +430,38 @@ func (s *ProductService) UpdateProduct(ctx context.Context, id string, req trans
 	return productResponse, nil
 }
 
-// DeleteProduct deletes a product by its UUID.
+// productBarcode trims a requested barcode, returning nil when none was given.
+func productBarcode(code *string) *string {
+	if code == nil || strings.TrimSpace(*code) == "" {
+		return nil
+	}
+
+	b := strings.TrimSpace(*code)
+	return &b
+}
+
+// DeleteProduct moves a product to the trash.
 func (s *ProductService) DeleteProduct(ctx context.Context, id string) error {
-	err := s.repo.DeleteProduct(ctx, id)
+	store, err := currentStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	product, err := s.repo.GetProductByUUID(ctx, store.ID, id)
+	if err != nil {
+		fmt.Print("s.repo.GetProductByUUID() Error: ", err.Error())
+		return err
+	}
+	if product == nil {
+		return repository.ErrProductNotFound
+	}
+
+	entry, err := newAuditEntry(ctx, model.AuditActionDelete, model.AuditEntityProduct, id, productSnapshot(*product), nil)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.DeleteProduct(ctx, store.ID, id, entry)
 	if err != nil {
 		fmt.Print("s.repo.DeleteProduct() Error: ", err.Error())
 		return err
@@ -251,3 +469,143 @@ func (s *ProductService) DeleteProduct(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// GetDeletedProducts lists the products in the trash one page at a time, newest first.
+func (s *ProductService) GetDeletedProducts(ctx context.Context, req transport.TrashListRequest) (transport.ProductTrashResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.ProductTrashResponse{}, err
+	}
+	beforeID, limit, err := parseCursorPage(req.Cursor, req.Limit)
+	if err != nil {
+		return transport.ProductTrashResponse{}, err
+	}
+
+	products, err := s.repo.GetDeletedProducts(ctx, store.ID, repository.TrashFilter{BeforeID: beforeID, Limit: limit + 1})
+	if err != nil {
+		fmt.Print("s.repo.GetDeletedProducts() Error: ", err.Error())
+		return transport.ProductTrashResponse{}, err
+	}
+
+	var response transport.ProductTrashResponse
+	if len(products) > limit {
+		products = products[:limit]
+		cursor := encodeCursor(products[limit-1].ID)
+		response.NextCursor = &cursor
+	}
+	response.Data = transformProduct(products)
+	if response.Data == nil {
+		response.Data = []transport.ProductItemResponse{}
+	}
+
+	return response, nil
+}
+
+// RestoreProduct takes a product out of the trash. While its category is in the trash
+// the product shows no category.
+func (s *ProductService) RestoreProduct(ctx context.Context, id string) (transport.ProductItemResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.ProductItemResponse{}, err
+	}
+
+	product, err := s.repo.GetDeletedProductByUUID(ctx, store.ID, id)
+	if err != nil {
+		fmt.Print("s.repo.GetDeletedProductByUUID() Error: ", err.Error())
+		return transport.ProductItemResponse{}, err
+	}
+	if product == nil {
+		return transport.ProductItemResponse{}, repository.ErrProductNotFound
+	}
+
+	entry, err := newAuditEntry(ctx, model.AuditActionRestore, model.AuditEntityProduct, id, nil, productSnapshot(*product))
+	if err != nil {
+		return transport.ProductItemResponse{}, err
+	}
+
+	err = s.repo.RestoreProduct(ctx, store.ID, id, entry)
+	if err != nil {
+		fmt.Print("s.repo.RestoreProduct() Error: ", err.Error())
+		return transport.ProductItemResponse{}, err
+	}
+
+	product.DeletedAt = nil
+	return transformProduct([]model.Product{*product})[0], nil
+}
+
+// AdjustStock applies a manual adjustment or a goods receipt to a product's stock and
+// records it in the stock ledger.
+func (s *ProductService) AdjustStock(ctx context.Context, id string, req transport.StockAdjustmentRequest) (*model.StockMovement, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.Type {
+	case model.StockMovementAdjustment:
+		if req.Delta == 0 {
+			return nil, repository.NewValidationError(repository.ErrInvalidStockAdjustment, "delta", "must not be zero")
+		}
+	case model.StockMovementReceiving:
+		if req.Delta <= 0 {
+			return nil, repository.NewValidationError(repository.ErrInvalidStockAdjustment, "delta", "must be positive when receiving stock")
+		}
+	default:
+		return nil, repository.NewValidationError(repository.ErrInvalidStockAdjustment, "type", fmt.Sprintf("must be %q or %q", model.StockMovementAdjustment, model.StockMovementReceiving))
+	}
+
+	req.Reference = strings.TrimSpace(req.Reference)
+	if req.Reference == "" {
+		return nil, repository.NewValidationError(repository.ErrInvalidStockAdjustment, "reference", "is required")
+	}
+
+	movement, err := s.stockRepo.AdjustStock(ctx, store.ID, id, model.StockMovement{
+		Type:      req.Type,
+		Delta:     req.Delta,
+		Reference: req.Reference,
+		Note:      req.Note,
+	})
+	if err != nil {
+		fmt.Print("s.stockRepo.AdjustStock() Error: ", err.Error())
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+// GetStockMovements lists a product's stock ledger newest first, one cursor page at a time.
+func (s *ProductService) GetStockMovements(ctx context.Context, id string, req transport.StockMovementListRequest) (transport.StockMovementListResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.StockMovementListResponse{}, err
+	}
+
+	product, err := s.repo.GetProductByUUID(ctx, store.ID, id)
+	if err != nil {
+		fmt.Print("s.repo.GetProductByUUID() Error: ", err.Error())
+		return transport.StockMovementListResponse{}, err
+	}
+	if product == nil {
+		return transport.StockMovementListResponse{}, repository.ErrProductNotFound
+	}
+
+	beforeID, limit, err := parseCursorPage(req.Cursor, req.Limit)
+	if err != nil {
+		return transport.StockMovementListResponse{}, err
+	}
+
+	movements, err := s.stockRepo.GetStockMovements(ctx, store.ID, id, beforeID, limit+1)
+	if err != nil {
+		fmt.Print("s.stockRepo.GetStockMovements() Error: ", err.Error())
+		return transport.StockMovementListResponse{}, err
+	}
+
+	response := transport.StockMovementListResponse{Data: movements}
+	if len(movements) > limit {
+		response.Data = movements[:limit]
+		cursor := encodeCursor(response.Data[limit-1].ID)
+		response.NextCursor = &cursor
+	}
+
+	return response, nil
+}
@@ -2,59 +2,387 @@ package service
 
 import (
 	"context"
+	"fendi/modul-03-task/model"
 	"fendi/modul-03-task/repository"
 	"fendi/modul-03-task/transport"
+	"fmt"
+	"math"
+	"strconv"
 	"time"
 )
 
+const (
+	// DefaultReportTop is the length of the best seller lists when the client does not ask for one.
+	DefaultReportTop = 5
+	// MaxReportTop is the longest best seller list a client may ask for.
+	MaxReportTop = 50
+)
+
+// Intervals of a sales time series. Weeks start on Monday.
+const (
+	IntervalHour  = "hour"
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// UncategorizedName names the group of products without a category in the category report.
+const UncategorizedName = "Uncategorized"
+
+// MaxSeriesBuckets bounds the length of a sales time series.
+const MaxSeriesBuckets = 10000
+
+// reportDateLayout is the format of report dates.
+const reportDateLayout = "2006-01-02"
+
 type ReportService struct {
-	repo *repository.ReportRepository
+	repo     repository.ReportRepository
+	location *time.Location
 }
 
-func NewReportService(repo *repository.ReportRepository) *ReportService {
-	return &ReportService{repo: repo}
+// NewReportService creates a ReportService. Report days follow location, the stores'
+// time zone, unless a request names another.
+func NewReportService(repo repository.ReportRepository, location *time.Location) *ReportService {
+	return &ReportService{repo: repo, location: location}
 }
 
-func (s *ReportService) GetTodayReport(ctx context.Context) (transport.ReportResponse, error) {
-	dateStart := time.Now().Format("2006-01-02") + " 00:00:00"
-	dateEnd := time.Now().Format("2006-01-02") + " 23:59:59"
+// GetTodayReport reports on today in the requested or store time zone.
+func (s *ReportService) GetTodayReport(ctx context.Context, req transport.ReportRequest) (transport.ReportResponse, error) {
+	req.StartDate = ""
+	req.EndDate = ""
 
-	report, err := s.repo.FetchReport(dateStart, dateEnd)
+	return s.GetReportByDate(ctx, req)
+}
+
+// GetReportByDate reports on the days from req.StartDate to req.EndDate.
+func (s *ReportService) GetReportByDate(ctx context.Context, req transport.ReportRequest) (transport.ReportResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.ReportResponse{}, err
+	}
+	rr, err := s.resolveRange(req.StartDate, req.EndDate, req.TZ)
+	if err != nil {
+		return transport.ReportResponse{}, err
+	}
+	top, err := parseReportTop(req.Top)
 	if err != nil {
 		return transport.ReportResponse{}, err
 	}
 
+	return s.report(ctx, store.ID, rr, top)
+}
+
+// parseReportTop validates the length of the best seller lists.
+func parseReportTop(top string) (int, error) {
+	if top == "" {
+		return DefaultReportTop, nil
+	}
+
+	n, err := strconv.Atoi(top)
+	if err != nil || n < 1 || n > MaxReportTop {
+		return 0, repository.NewValidationError(ErrInvalidQuery, "top", fmt.Sprintf("must be between 1 and %d", MaxReportTop))
+	}
+
+	return n, nil
+}
+
+// report reports on the sales of a store during rr with best seller lists of top products.
+func (s *ReportService) report(ctx context.Context, storeID int64, rr reportRange, top int) (transport.ReportResponse, error) {
+	report, err := s.repo.FetchReport(ctx, storeID, repository.ReportFilter{From: rr.From, To: rr.To, Top: top})
+	if err != nil {
+		fmt.Print("s.repo.FetchReport() Error: ", err.Error())
+		return transport.ReportResponse{}, err
+	}
+
 	response := transport.ReportResponse{
+		Range:            rr.response(),
 		TotalRevenue:     report.TotalRevenue,
 		TotalTransaction: report.TotalTransaction,
-		MostPurchasedItem: &transport.MostPurchasedItemResponse{
-			ProductID:   report.MostPurchasedItem.ProductID,
-			ProductName: report.MostPurchasedItem.ProductName,
-			Quantity:    report.MostPurchasedItem.Quantity,
-		},
+		ItemsSold:        report.ItemsSold,
+		TopByQuantity:    transformProductSales(report.TopByQuantity),
+		TopByRevenue:     transformProductSales(report.TopByRevenue),
+	}
+	// Like total_revenue, the average basket is net of the period's refunds.
+	if report.TotalTransaction > 0 {
+		response.AverageBasket = report.TotalRevenue.Div(report.TotalTransaction)
+	}
+	if len(response.TopByQuantity) > 0 {
+		response.MostPurchasedItem = &response.TopByQuantity[0]
 	}
 
 	return response, nil
 }
 
-func (s *ReportService) GetReportByDate(ctx context.Context, startDate, endDate string) (transport.ReportResponse, error) {
-	dateStart := startDate + " 00:00:00"
-	dateEnd := endDate + " 23:59:59"
+// transformProductSales transforms report product figures to their response form.
+func transformProductSales(sales []model.ProductSales) []transport.ProductSalesResponse {
+	response := make([]transport.ProductSalesResponse, 0, len(sales))
+	for _, s := range sales {
+		response = append(response, transport.ProductSalesResponse{
+			ProductID:   s.ProductID,
+			ProductName: s.ProductName,
+			Quantity:    s.Quantity,
+			Revenue:     s.Revenue,
+		})
+	}
+
+	return response
+}
 
-	report, err := s.repo.FetchReport(dateStart, dateEnd)
+// GetCategoryReport groups the sales of a date range by category, highest revenue
+// first, with each category's share of the total revenue.
+func (s *ReportService) GetCategoryReport(ctx context.Context, req transport.ReportRequest) (transport.CategoryReportResponse, error) {
+	store, err := currentStore(ctx)
 	if err != nil {
-		return transport.ReportResponse{}, err
+		return transport.CategoryReportResponse{}, err
+	}
+	rr, err := s.resolveRange(req.StartDate, req.EndDate, req.TZ)
+	if err != nil {
+		return transport.CategoryReportResponse{}, err
 	}
 
-	response := transport.ReportResponse{
-		TotalRevenue:     report.TotalRevenue,
-		TotalTransaction: report.TotalTransaction,
-		MostPurchasedItem: &transport.MostPurchasedItemResponse{
-			ProductID:   report.MostPurchasedItem.ProductID,
-			ProductName: report.MostPurchasedItem.ProductName,
-			Quantity:    report.MostPurchasedItem.Quantity,
-		},
+	return s.categoryReport(ctx, store.ID, rr)
+}
+
+// categoryReport groups the sales of a store during rr by category.
+func (s *ReportService) categoryReport(ctx context.Context, storeID int64, rr reportRange) (transport.CategoryReportResponse, error) {
+	categories, err := s.repo.FetchCategorySales(ctx, storeID, repository.ReportFilter{From: rr.From, To: rr.To})
+	if err != nil {
+		fmt.Print("s.repo.FetchCategorySales() Error: ", err.Error())
+		return transport.CategoryReportResponse{}, err
+	}
+
+	response := transport.CategoryReportResponse{Range: rr.response(), Data: make([]transport.CategorySalesResponse, 0, len(categories))}
+	for _, c := range categories {
+		response.TotalRevenue += c.Revenue
+	}
+	for _, c := range categories {
+		item := transport.CategorySalesResponse{
+			ID:               c.CategoryID,
+			Name:             c.CategoryName,
+			Deleted:          c.Deleted,
+			TotalRevenue:     c.Revenue,
+			ItemsSold:        c.Quantity,
+			TotalTransaction: c.Transactions,
+		}
+		if c.CategoryID == nil {
+			item.Name = UncategorizedName
+		}
+		if response.TotalRevenue > 0 {
+			item.Share = math.Round(float64(c.Revenue)*10000/float64(response.TotalRevenue)) / 100
+		}
+		response.Data = append(response.Data, item)
 	}
 
 	return response, nil
 }
+
+// GetStoreReport rolls the sales of a date range up across every store, with each
+// store's figures and share of the total revenue. Stores keep their creation order.
+func (s *ReportService) GetStoreReport(ctx context.Context, req transport.ReportRequest) (transport.StoreReportResponse, error) {
+	rr, err := s.resolveRange(req.StartDate, req.EndDate, req.TZ)
+	if err != nil {
+		return transport.StoreReportResponse{}, err
+	}
+
+	stores, err := s.repo.FetchStoreSales(ctx, repository.ReportFilter{From: rr.From, To: rr.To})
+	if err != nil {
+		fmt.Print("s.repo.FetchStoreSales() Error: ", err.Error())
+		return transport.StoreReportResponse{}, err
+	}
+
+	response := transport.StoreReportResponse{Range: rr.response(), Data: make([]transport.StoreSalesResponse, 0, len(stores))}
+	for _, st := range stores {
+		response.TotalRevenue += st.GrossSales - st.Refunded
+		response.TotalTransaction += st.Transactions
+		response.ItemsSold += st.ItemsSold
+	}
+	if response.TotalTransaction > 0 {
+		response.AverageBasket = response.TotalRevenue.Div(response.TotalTransaction)
+	}
+
+	for _, st := range stores {
+		item := transport.StoreSalesResponse{
+			ID:               st.Store.UUID,
+			Name:             st.Store.Name,
+			TotalRevenue:     st.GrossSales - st.Refunded,
+			TotalTransaction: st.Transactions,
+			ItemsSold:        st.ItemsSold,
+		}
+		if st.Transactions > 0 {
+			item.AverageBasket = item.TotalRevenue.Div(st.Transactions)
+		}
+		if response.TotalRevenue > 0 {
+			item.Share = math.Round(float64(item.TotalRevenue)*10000/float64(response.TotalRevenue)) / 100
+		}
+		response.Data = append(response.Data, item)
+	}
+
+	return response, nil
+}
+
+// GetSalesSeries buckets the revenue, transactions and units of a date range by
+// interval in the requested or store time zone. Buckets without sales are reported
+// with zeros.
+func (s *ReportService) GetSalesSeries(ctx context.Context, req transport.SalesSeriesRequest) (transport.SalesSeriesResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.SalesSeriesResponse{}, err
+	}
+
+	interval := req.Interval
+	if interval == "" {
+		interval = IntervalDay
+	}
+	switch interval {
+	case IntervalHour, IntervalDay, IntervalWeek, IntervalMonth:
+	default:
+		return transport.SalesSeriesResponse{}, repository.NewValidationError(ErrInvalidQuery, "interval", fmt.Sprintf("must be one of %s, %s, %s, %s",
+			IntervalHour, IntervalDay, IntervalWeek, IntervalMonth))
+	}
+
+	rr, err := s.resolveRange(req.StartDate, req.EndDate, req.TZ)
+	if err != nil {
+		return transport.SalesSeriesResponse{}, err
+	}
+
+	var buckets []transport.SalesBucketResponse
+	for t := bucketStart(rr.From.In(rr.Location), interval); t.Before(rr.To); t = nextBucket(t, interval) {
+		if len(buckets) == MaxSeriesBuckets {
+			return transport.SalesSeriesResponse{}, repository.NewValidationError(ErrInvalidQuery, "interval",
+				fmt.Sprintf("gives more than %d buckets for this range, use a longer interval or a shorter range", MaxSeriesBuckets))
+		}
+		buckets = append(buckets, transport.SalesBucketResponse{Start: t})
+	}
+
+	entries, err := s.repo.FetchSalesEntries(ctx, store.ID, repository.ReportFilter{From: rr.From, To: rr.To})
+	if err != nil {
+		fmt.Print("s.repo.FetchSalesEntries() Error: ", err.Error())
+		return transport.SalesSeriesResponse{}, err
+	}
+
+	// Entries come in time order, so the bucket index only moves forward.
+	i := 0
+	for _, e := range entries {
+		for i+1 < len(buckets) && !e.At.Before(buckets[i+1].Start) {
+			i++
+		}
+		buckets[i].TotalRevenue += e.Revenue
+		buckets[i].TotalTransaction += e.Transactions
+		buckets[i].ItemsSold += e.ItemsSold
+	}
+
+	return transport.SalesSeriesResponse{Range: rr.response(), Interval: interval, Data: buckets}, nil
+}
+
+// reportRange is a validated report period: the days from StartDate to EndDate in
+// Location, which is the half-open range From to To in UTC.
+type reportRange struct {
+	StartDate string
+	EndDate   string
+	Location  *time.Location
+	From      time.Time
+	To        time.Time
+}
+
+// resolveRange validates the date range and time zone of a report. end_date defaults
+// to today and start_date to end_date; tz defaults to the store time zone.
+func (s *ReportService) resolveRange(startDate, endDate, tz string) (reportRange, error) {
+	location, err := loadLocation(tz, s.location)
+	if err != nil {
+		return reportRange{}, err
+	}
+
+	if endDate == "" {
+		endDate = time.Now().In(location).Format(reportDateLayout)
+	}
+	if startDate == "" {
+		startDate = endDate
+	}
+
+	start, err := parseDay("start_date", startDate, location)
+	if err != nil {
+		return reportRange{}, err
+	}
+	end, err := parseDay("end_date", endDate, location)
+	if err != nil {
+		return reportRange{}, err
+	}
+	if end.Before(start) {
+		return reportRange{}, repository.NewValidationError(ErrInvalidQuery, "end_date", "must not be before start_date")
+	}
+
+	return reportRange{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Location:  location,
+		From:      start.UTC(),
+		// The range covers end_date in full.
+		To: end.AddDate(0, 0, 1).UTC(),
+	}, nil
+}
+
+// loadLocation returns the IANA time zone tz, or fallback when tz is empty.
+func loadLocation(tz string, fallback *time.Location) (*time.Location, error) {
+	if tz == "" {
+		return fallback, nil
+	}
+
+	location, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, repository.NewValidationError(ErrInvalidQuery, "tz", "must be an IANA time zone such as Asia/Jakarta")
+	}
+
+	return location, nil
+}
+
+// parseDay returns the start of the YYYY-MM-DD date value in location. field is the
+// query parameter reported when value is not a date.
+func parseDay(field, value string, location *time.Location) (time.Time, error) {
+	day, err := time.ParseInLocation(reportDateLayout, value, location)
+	if err != nil {
+		return time.Time{}, repository.NewValidationError(ErrInvalidQuery, field, "must be a date in YYYY-MM-DD format")
+	}
+
+	return day, nil
+}
+
+func (rr reportRange) response() transport.ReportRangeResponse {
+	return transport.ReportRangeResponse{
+		StartDate: rr.StartDate,
+		EndDate:   rr.EndDate,
+		Timezone:  rr.Location.String(),
+		From:      rr.From,
+		To:        rr.To,
+	}
+}
+
+// bucketStart returns the start of the interval bucket t falls in.
+func bucketStart(t time.Time, interval string) time.Time {
+	y, m, d := t.Date()
+	switch interval {
+	case IntervalHour:
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+	case IntervalWeek:
+		// Monday is the first day of the week.
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+	case IntervalMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// nextBucket returns the start of the bucket after the one starting at t.
+func nextBucket(t time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalHour:
+		return t.Add(time.Hour)
+	case IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	case IntervalMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
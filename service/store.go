@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"fendi/modul-03-task/transport"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrStoreRequired is returned when a request works on store data but no store
+	// was resolved for it, e.g. an owner without X-Store-ID on a multi-store deployment.
+	ErrStoreRequired = errors.New("pick a store with the X-Store-ID header")
+	// ErrStoreForbidden is returned when a user bound to a store asks for another one.
+	ErrStoreForbidden = errors.New("you may only work in your own store")
+)
+
+type storeKey struct{}
+
+// WithStore returns a copy of ctx carrying the store a request works in.
+func WithStore(ctx context.Context, store model.Store) context.Context {
+	return context.WithValue(ctx, storeKey{}, store)
+}
+
+// StoreFromContext returns the store stored by WithStore.
+func StoreFromContext(ctx context.Context) (model.Store, bool) {
+	store, ok := ctx.Value(storeKey{}).(model.Store)
+	return store, ok
+}
+
+// currentStore returns the store of the request, or ErrStoreRequired.
+func currentStore(ctx context.Context) (model.Store, error) {
+	store, ok := StoreFromContext(ctx)
+	if !ok {
+		return model.Store{}, ErrStoreRequired
+	}
+
+	return store, nil
+}
+
+type StoreService struct {
+	repo repository.StoreRepository
+}
+
+func NewStoreService(repo repository.StoreRepository) *StoreService {
+	return &StoreService{repo: repo}
+}
+
+// GetAllStore lists every store, oldest first.
+func (s *StoreService) GetAllStore(ctx context.Context) (transport.StoreListResponse, error) {
+	stores, err := s.repo.GetAllStore(ctx)
+	if err != nil {
+		fmt.Print("s.repo.GetAllStore() Error: ", err.Error())
+		return transport.StoreListResponse{}, err
+	}
+
+	response := transport.StoreListResponse{Data: make([]transport.StoreResponse, 0, len(stores))}
+	for _, st := range stores {
+		response.Data = append(response.Data, transformStore(st))
+	}
+
+	return response, nil
+}
+
+// GetStoreByUUID retrieves a store by its UUID.
+func (s *StoreService) GetStoreByUUID(ctx context.Context, uuid string) (transport.StoreResponse, error) {
+	store, err := getStore(ctx, s.repo, uuid)
+	if err != nil {
+		return transport.StoreResponse{}, err
+	}
+
+	return transformStore(*store), nil
+}
+
+// CreateStore opens a new store without categories or products.
+func (s *StoreService) CreateStore(ctx context.Context, req transport.StoreRequest) (transport.StoreResponse, error) {
+	store := model.Store{
+		UUID:      helper.GenerateUUID(),
+		Name:      strings.TrimSpace(req.Name),
+		Address:   strings.TrimSpace(req.Address),
+		CreatedAt: time.Now().UTC(),
+	}
+	err := s.repo.CreateStore(ctx, store)
+	if err != nil {
+		fmt.Print("s.repo.CreateStore() Error: ", err.Error())
+		return transport.StoreResponse{}, err
+	}
+
+	return transformStore(store), nil
+}
+
+// UpdateStore renames a store or changes its address.
+func (s *StoreService) UpdateStore(ctx context.Context, uuid string, req transport.StoreRequest) (transport.StoreResponse, error) {
+	store, err := getStore(ctx, s.repo, uuid)
+	if err != nil {
+		return transport.StoreResponse{}, err
+	}
+
+	store.Name = strings.TrimSpace(req.Name)
+	store.Address = strings.TrimSpace(req.Address)
+	err = s.repo.UpdateStore(ctx, *store)
+	if err != nil {
+		fmt.Print("s.repo.UpdateStore() Error: ", err.Error())
+		return transport.StoreResponse{}, err
+	}
+
+	return transformStore(*store), nil
+}
+
+// getStore retrieves a store by its UUID, or repository.ErrStoreNotFound.
+func getStore(ctx context.Context, repo repository.StoreRepository, uuid string) (*model.Store, error) {
+	store, err := repo.GetStoreByUUID(ctx, uuid)
+	if err != nil {
+		fmt.Print("s.repo.GetStoreByUUID() Error: ", err.Error())
+		return nil, err
+	}
+	if store == nil {
+		return nil, repository.ErrStoreNotFound
+	}
+
+	return store, nil
+}
+
+// transformStore transforms a model.Store to its response form.
+func transformStore(st model.Store) transport.StoreResponse {
+	return transport.StoreResponse{ID: st.UUID, Name: st.Name, Address: st.Address, CreatedAt: st.CreatedAt}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"fendi/modul-03-task/transport"
+	"fmt"
+	"time"
+)
+
+type TrashService struct {
+	productRepo  repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+	location     *time.Location
+}
+
+// NewTrashService creates a TrashService. Purge cutoffs are calendar days in location.
+func NewTrashService(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, location *time.Location) *TrashService {
+	return &TrashService{productRepo: productRepo, categoryRepo: categoryRepo, location: location}
+}
+
+// PurgeTrash removes the products and categories of the current store deleted before
+// the start of req.Before for good. Each removed record gets a purge entry in the
+// audit trail. Products that were ever sold stay in the trash so the sales history
+// keeps them.
+func (s *TrashService) PurgeTrash(ctx context.Context, req transport.PurgeRequest) (transport.PurgeResponse, error) {
+	store, err := currentStore(ctx)
+	if err != nil {
+		return transport.PurgeResponse{}, err
+	}
+
+	before, err := time.ParseInLocation("2006-01-02", req.Before, s.location)
+	if err != nil {
+		return transport.PurgeResponse{}, repository.NewValidationError(repository.ErrInvalidRequest, "before", "must be in YYYY-MM-DD format")
+	}
+	filter := repository.TrashFilter{DeletedBefore: &before}
+
+	products, err := s.productRepo.GetDeletedProducts(ctx, store.ID, filter)
+	if err != nil {
+		fmt.Print("s.productRepo.GetDeletedProducts() Error: ", err.Error())
+		return transport.PurgeResponse{}, err
+	}
+
+	entries := make([]model.AuditEntry, 0, len(products))
+	for _, p := range products {
+		entry, err := newAuditEntry(ctx, model.AuditActionPurge, model.AuditEntityProduct, p.UUID, productSnapshot(p), nil)
+		if err != nil {
+			return transport.PurgeResponse{}, err
+		}
+		entries = append(entries, entry)
+	}
+
+	purgedProducts, err := s.productRepo.PurgeProducts(ctx, store.ID, entries)
+	if err != nil {
+		fmt.Print("s.productRepo.PurgeProducts() Error: ", err.Error())
+		return transport.PurgeResponse{}, err
+	}
+
+	categories, err := s.categoryRepo.GetDeletedCategories(ctx, store.ID, filter)
+	if err != nil {
+		fmt.Print("s.categoryRepo.GetDeletedCategories() Error: ", err.Error())
+		return transport.PurgeResponse{}, err
+	}
+
+	entries = make([]model.AuditEntry, 0, len(categories))
+	for _, c := range categories {
+		entry, err := newAuditEntry(ctx, model.AuditActionPurge, model.AuditEntityCategory, c.UUID, categorySnapshot(c), nil)
+		if err != nil {
+			return transport.PurgeResponse{}, err
+		}
+		entries = append(entries, entry)
+	}
+
+	purgedCategories, err := s.categoryRepo.PurgeCategories(ctx, store.ID, entries)
+	if err != nil {
+		fmt.Print("s.categoryRepo.PurgeCategories() Error: ", err.Error())
+		return transport.PurgeResponse{}, err
+	}
+
+	response := transport.PurgeResponse{
+		Before:           req.Before,
+		PurgedProducts:   purgedProducts,
+		PurgedCategories: purgedCategories,
+		KeptProducts:     int64(len(products)) - purgedProducts,
+	}
+
+	return response, nil
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"fendi/modul-03-task/transport"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrLastOwner is returned when a change would leave no owner.
+var ErrLastOwner = errors.New("the last owner cannot be deleted or given another role")
+
+type UserService struct {
+	repo      repository.UserRepository
+	storeRepo repository.StoreRepository
+}
+
+func NewUserService(repo repository.UserRepository, storeRepo repository.StoreRepository) *UserService {
+	return &UserService{repo: repo, storeRepo: storeRepo}
+}
+
+// GetAllUser lists every user, oldest first.
+func (s *UserService) GetAllUser(ctx context.Context) (transport.UserListResponse, error) {
+	users, err := s.repo.GetAllUser(ctx)
+	if err != nil {
+		fmt.Print("s.repo.GetAllUser() Error: ", err.Error())
+		return transport.UserListResponse{}, err
+	}
+
+	response := transport.UserListResponse{Data: make([]transport.UserResponse, 0, len(users))}
+	for _, u := range users {
+		response.Data = append(response.Data, transformUser(u))
+	}
+
+	return response, nil
+}
+
+// GetUserByUUID retrieves a user by its UUID.
+func (s *UserService) GetUserByUUID(ctx context.Context, uuid string) (transport.UserResponse, error) {
+	user, err := s.getUser(ctx, uuid)
+	if err != nil {
+		return transport.UserResponse{}, err
+	}
+
+	return transformUser(*user), nil
+}
+
+// CreateUser creates a user without API keys.
+func (s *UserService) CreateUser(ctx context.Context, req transport.UserRequest) (transport.UserResponse, error) {
+	err := validateRole(req.Role)
+	if err != nil {
+		return transport.UserResponse{}, err
+	}
+	store, err := s.userStore(ctx, req)
+	if err != nil {
+		return transport.UserResponse{}, err
+	}
+
+	user := model.User{
+		UUID:      helper.GenerateUUID(),
+		Name:      req.Name,
+		Role:      req.Role,
+		Store:     store,
+		CreatedAt: time.Now().UTC(),
+	}
+	err = s.repo.CreateUser(ctx, user)
+	if err != nil {
+		fmt.Print("s.repo.CreateUser() Error: ", err.Error())
+		return transport.UserResponse{}, err
+	}
+
+	return transformUser(user), nil
+}
+
+// UpdateUser renames a user or changes its role or store. The last owner keeps its role.
+func (s *UserService) UpdateUser(ctx context.Context, uuid string, req transport.UserRequest) (transport.UserResponse, error) {
+	err := validateRole(req.Role)
+	if err != nil {
+		return transport.UserResponse{}, err
+	}
+	store, err := s.userStore(ctx, req)
+	if err != nil {
+		return transport.UserResponse{}, err
+	}
+
+	user, err := s.getUser(ctx, uuid)
+	if err != nil {
+		return transport.UserResponse{}, err
+	}
+	if user.Role == model.RoleOwner && req.Role != model.RoleOwner {
+		err = s.checkNotLastOwner(ctx)
+		if err != nil {
+			return transport.UserResponse{}, err
+		}
+	}
+
+	user.Name = req.Name
+	user.Role = req.Role
+	user.Store = store
+	err = s.repo.UpdateUser(ctx, *user)
+	if err != nil {
+		fmt.Print("s.repo.UpdateUser() Error: ", err.Error())
+		return transport.UserResponse{}, err
+	}
+
+	return transformUser(*user), nil
+}
+
+// DeleteUser deletes a user, which stops all its API keys from working. The last
+// owner cannot be deleted.
+func (s *UserService) DeleteUser(ctx context.Context, uuid string) error {
+	user, err := s.getUser(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if user.Role == model.RoleOwner {
+		err = s.checkNotLastOwner(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.repo.DeleteUser(ctx, uuid)
+	if err != nil {
+		fmt.Print("s.repo.DeleteUser() Error: ", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetAPIKeys lists the API keys of a user, revoked ones included.
+func (s *UserService) GetAPIKeys(ctx context.Context, userUUID string) (transport.APIKeyListResponse, error) {
+	user, err := s.getUser(ctx, userUUID)
+	if err != nil {
+		return transport.APIKeyListResponse{}, err
+	}
+
+	keys, err := s.repo.GetAPIKeysByUser(ctx, user.ID)
+	if err != nil {
+		fmt.Print("s.repo.GetAPIKeysByUser() Error: ", err.Error())
+		return transport.APIKeyListResponse{}, err
+	}
+
+	response := transport.APIKeyListResponse{Data: make([]transport.APIKeyResponse, 0, len(keys))}
+	for _, k := range keys {
+		response.Data = append(response.Data, transformAPIKey(k))
+	}
+
+	return response, nil
+}
+
+// CreateAPIKey issues a new API key for a user. The key itself is only returned here.
+func (s *UserService) CreateAPIKey(ctx context.Context, userUUID string, req transport.APIKeyRequest) (transport.CreatedAPIKeyResponse, error) {
+	user, err := s.getUser(ctx, userUUID)
+	if err != nil {
+		return transport.CreatedAPIKeyResponse{}, err
+	}
+
+	key, prefix, hash, err := generateAPIKey()
+	if err != nil {
+		fmt.Print("generateAPIKey() Error: ", err.Error())
+		return transport.CreatedAPIKeyResponse{}, err
+	}
+
+	apiKey := model.APIKey{
+		UUID:      helper.GenerateUUID(),
+		UserID:    user.ID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    prefix,
+		Hash:      hash,
+		CreatedAt: time.Now().UTC(),
+	}
+	err = s.repo.CreateAPIKey(ctx, apiKey)
+	if err != nil {
+		fmt.Print("s.repo.CreateAPIKey() Error: ", err.Error())
+		return transport.CreatedAPIKeyResponse{}, err
+	}
+
+	return transport.CreatedAPIKeyResponse{APIKeyResponse: transformAPIKey(apiKey), Key: key}, nil
+}
+
+// RevokeAPIKey stops an API key from working. Revoking a revoked key does nothing.
+func (s *UserService) RevokeAPIKey(ctx context.Context, uuid string) error {
+	key, err := s.repo.GetAPIKeyByUUID(ctx, uuid)
+	if err != nil {
+		fmt.Print("s.repo.GetAPIKeyByUUID() Error: ", err.Error())
+		return err
+	}
+	if key == nil {
+		return repository.ErrAPIKeyNotFound
+	}
+
+	err = s.repo.RevokeAPIKey(ctx, uuid, time.Now())
+	if err != nil {
+		fmt.Print("s.repo.RevokeAPIKey() Error: ", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// getUser retrieves a live user by its UUID.
+func (s *UserService) getUser(ctx context.Context, uuid string) (*model.User, error) {
+	user, err := s.repo.GetUserByUUID(ctx, uuid)
+	if err != nil {
+		fmt.Print("s.repo.GetUserByUUID() Error: ", err.Error())
+		return nil, err
+	}
+	if user == nil {
+		return nil, repository.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+// checkNotLastOwner fails with ErrLastOwner when there is only one owner left.
+func (s *UserService) checkNotLastOwner(ctx context.Context) error {
+	owners, err := s.repo.CountUserByRole(ctx, model.RoleOwner)
+	if err != nil {
+		fmt.Print("s.repo.CountUserByRole() Error: ", err.Error())
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+
+	return nil
+}
+
+// userStore returns the store named by req. Owners work in every store and must not
+// name one; managers and cashiers must.
+func (s *UserService) userStore(ctx context.Context, req transport.UserRequest) (*model.Store, error) {
+	if req.Role == model.RoleOwner {
+		if req.StoreID != "" {
+			return nil, repository.NewValidationError(repository.ErrInvalidRequest, "store_id", "must be empty for owners")
+		}
+		return nil, nil
+	}
+	if req.StoreID == "" {
+		return nil, repository.NewValidationError(repository.ErrInvalidRequest, "store_id", "is required for managers and cashiers")
+	}
+
+	store, err := s.storeRepo.GetStoreByUUID(ctx, req.StoreID)
+	if err != nil {
+		fmt.Print("s.storeRepo.GetStoreByUUID() Error: ", err.Error())
+		return nil, err
+	}
+	if store == nil {
+		return nil, repository.NewValidationError(repository.ErrInvalidRequest, "store_id", "must be an existing store")
+	}
+
+	return store, nil
+}
+
+// validateRole checks that role is one of model.Roles.
+func validateRole(role string) error {
+	if !slices.Contains(model.Roles, role) {
+		return repository.NewValidationError(repository.ErrInvalidRequest, "role", "must be one of "+strings.Join(model.Roles, ", "))
+	}
+
+	return nil
+}
+
+// transformUser transforms a model.User to its response form.
+func transformUser(u model.User) transport.UserResponse {
+	response := transport.UserResponse{ID: u.UUID, Name: u.Name, Role: u.Role, CreatedAt: u.CreatedAt}
+	if u.Store != nil {
+		store := transformStore(*u.Store)
+		response.Store = &store
+	}
+
+	return response
+}
+
+// transformAPIKey transforms a model.APIKey to its response form, without the key.
+func transformAPIKey(k model.APIKey) transport.APIKeyResponse {
+	return transport.APIKeyResponse{
+		ID:         k.UUID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		CreatedAt:  k.CreatedAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+	}
+}
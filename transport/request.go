@@ -1,28 +1,180 @@
 package transport
 
+import "fendi/modul-03-task/model"
+
 // CategoryRequest represents the payload for creating or updating a category.
 type CategoryRequest struct {
 	UUID        *string `json:"uuid"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
+	Name        string  `json:"name" validate:"required,max=255"`
+	Description string  `json:"description" validate:"max=1000"`
 }
 
 // ProductRequest represents the payload for creating or updating a product.
 type ProductRequest struct {
-	UUID       *string  `json:"uuid"`
-	Name       string   `json:"name"`
-	Stock      *int64   `json:"stock"`
-	Price      *float64 `json:"price"`
-	CategoryID string   `json:"category_id"`
+	UUID       *string      `json:"uuid"`
+	SKU        *string      `json:"sku" validate:"max=64"`
+	Barcode    *string      `json:"barcode" validate:"max=64"`
+	Name       string       `json:"name" validate:"required,max=255"`
+	Stock      *int64       `json:"stock" validate:"min=0,max=2147483647"`
+	Price      *model.Money `json:"price" validate:"min=0,max=99999999.99"`
+	CategoryID string       `json:"category_id" validate:"uuid"`
+}
+
+// CategoryListRequest represents the query parameters for listing categories.
+type CategoryListRequest struct {
+	Search string
+	Sort   string
+	Page   string
+	Limit  string
+	Cursor string
+}
+
+// ProductListRequest represents the query parameters for listing products.
+type ProductListRequest struct {
+	Search     string
+	CategoryID string
+	SKU        string
+	Barcode    string
+	MinPrice   string
+	MaxPrice   string
+	Stock      string
+	Sort       string
+	Page       string
+	Limit      string
+	Cursor     string
 }
 
 // CheckoutRequest represents the payload for checking out products.
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items"`
+	Items []CheckoutItem `json:"items" validate:"required"`
 }
 
-// CheckoutItem represents an item in the checkout request.
+// CheckoutItem represents an item in the checkout request. The product is referenced
+// by exactly one of its UUID, SKU or barcode.
 type CheckoutItem struct {
-	ID       string `json:"id"`
-	Quantity int64  `json:"quantity"`
+	ID       string `json:"id,omitempty" validate:"uuid"`
+	SKU      string `json:"sku,omitempty" validate:"max=64"`
+	Barcode  string `json:"barcode,omitempty" validate:"max=64"`
+	Quantity int64  `json:"quantity" validate:"min=1,max=1000000"`
+}
+
+// Reference returns how the item refers to its product, for example
+// "sku ITEM-1" or the bare UUID.
+func (i CheckoutItem) Reference() string {
+	switch {
+	case i.SKU != "":
+		return "sku " + i.SKU
+	case i.Barcode != "":
+		return "barcode " + i.Barcode
+	default:
+		return i.ID
+	}
+}
+
+// TransactionListRequest represents the query parameters for listing transactions.
+type TransactionListRequest struct {
+	StartDate string
+	EndDate   string
+	TZ        string
+	MinAmount string
+	MaxAmount string
+	ProductID string
+	Cursor    string
+	Limit     string
+}
+
+// TrashListRequest represents the query parameters for listing deleted products or
+// categories.
+type TrashListRequest struct {
+	Cursor string
+	Limit  string
+}
+
+// PurgeRequest represents the payload for purging the trash. Before is a date in
+// YYYY-MM-DD format; records deleted before that day are removed.
+type PurgeRequest struct {
+	Before string `json:"before" validate:"required"`
+}
+
+// AuditListRequest represents the query parameters for listing audit entries.
+type AuditListRequest struct {
+	Entity    string
+	EntityID  string
+	Action    string
+	StartDate string
+	EndDate   string
+	Cursor    string
+	Limit     string
+}
+
+// RefundRequest represents the payload for voiding or refunding a transaction.
+// Items are ignored for a void.
+type RefundRequest struct {
+	Reason string       `json:"reason"`
+	Items  []RefundItem `json:"items"`
+}
+
+// RefundItem represents a product and quantity to refund.
+type RefundItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int64  `json:"quantity"`
+}
+
+// StockAdjustmentRequest represents the payload for adjusting a product's stock.
+// Type is either "adjustment" or "receiving".
+type StockAdjustmentRequest struct {
+	Type      string  `json:"type"`
+	Delta     int64   `json:"delta" validate:"min=-2147483647,max=2147483647"`
+	Reference string  `json:"reference"`
+	Note      *string `json:"note"`
+}
+
+// StockMovementListRequest represents the query parameters for listing stock movements.
+type StockMovementListRequest struct {
+	Cursor string
+	Limit  string
+}
+
+// LabelRequest represents the query parameters for rendering labels. Page only applies
+// to label sheets.
+type LabelRequest struct {
+	Format string
+	Page   string
+}
+
+// ReportRequest represents the query parameters of a sales report. The dates are
+// ignored by the report of today.
+type ReportRequest struct {
+	StartDate string
+	EndDate   string
+	TZ        string
+	Top       string
+}
+
+// SalesSeriesRequest represents the query parameters of a sales time series.
+type SalesSeriesRequest struct {
+	StartDate string
+	EndDate   string
+	TZ        string
+	Interval  string
+}
+
+// UserRequest represents the payload for creating or updating a user. StoreID is
+// the store a manager or cashier works in; owners have none.
+type UserRequest struct {
+	Name    string `json:"name" validate:"required,max=255"`
+	Role    string `json:"role" validate:"required"`
+	StoreID string `json:"store_id"`
+}
+
+// APIKeyRequest represents the payload for issuing an API key. Name tells the key
+// apart from the user's others, e.g. "front counter tablet".
+type APIKeyRequest struct {
+	Name string `json:"name" validate:"max=255"`
+}
+
+// StoreRequest represents the payload for creating or updating a store.
+type StoreRequest struct {
+	Name    string `json:"name" validate:"required,max=255"`
+	Address string `json:"address" validate:"max=1000"`
 }
@@ -1,54 +1,287 @@
 package transport
 
+import (
+	"fendi/modul-03-task/model"
+	"io"
+	"time"
+)
+
 // StatusResponse represents a standard status response.
 type StatusResponse struct {
 	Code   int    `json:"code"`
 	Status string `json:"status"`
 }
 
+// ErrorResponse represents the body of every error response. Code is a stable,
+// machine-readable identifier; Details lists the offending fields, if any.
+type ErrorResponse struct {
+	Code    string                `json:"code"`
+	Message string                `json:"message"`
+	Details []ErrorDetailResponse `json:"details,omitempty"`
+}
+
+// ErrorDetailResponse describes what is wrong with a single request field.
+type ErrorDetailResponse struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
 // ProductItemResponse represents a product item in the response.
 type ProductItemResponse struct {
-	ID       string                `json:"id"`
-	Name     string                `json:"name"`
-	Stock    *int64                `json:"stock"`
-	Price    *float64              `json:"price"`
-	Category *CategoryItemResponse `json:"category"`
+	ID        string                `json:"id"`
+	SKU       string                `json:"sku"`
+	Barcode   *string               `json:"barcode"`
+	Name      string                `json:"name"`
+	Stock     *int64                `json:"stock"`
+	Price     *model.Money          `json:"price"`
+	Category  *CategoryItemResponse `json:"category"`
+	DeletedAt *time.Time            `json:"deleted_at,omitempty"`
 }
 
 // CategoryItemResponse represents a category item in the response.
 type CategoryItemResponse struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description *string `json:"description"`
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ListMeta describes a page of a listing. Page is set for page-based requests and
+// NextCursor is null on the last page.
+type ListMeta struct {
+	Total      int64   `json:"total"`
+	Limit      int     `json:"limit"`
+	Page       *int    `json:"page,omitempty"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// CategoryListResponse represents a page of categories.
+type CategoryListResponse struct {
+	Data []CategoryItemResponse `json:"data"`
+	Meta ListMeta               `json:"meta"`
+}
+
+// ProductListResponse represents a page of products.
+type ProductListResponse struct {
+	Data []ProductItemResponse `json:"data"`
+	Meta ListMeta              `json:"meta"`
 }
 
 // CheckoutResponse represents the response for a checkout operation.
 type CheckoutResponse struct {
 	ID          string                 `json:"id"`
 	Date        string                 `json:"date"`
-	TotalAmount float64                `json:"total_amount"`
+	TotalAmount model.Money            `json:"total_amount"`
 	Items       []CheckoutItemResponse `json:"items"`
 }
 
 // CheckoutItemResponse represents an item in the checkout response.
 type CheckoutItemResponse struct {
-	ProductID   string  `json:"product_id"`
-	ProductName string  `json:"product_name"`
-	Quantity    int64   `json:"quantity"`
-	UnitPrice   float64 `json:"unit_price"`
-	TotalPrice  float64 `json:"total_price"`
+	ProductID   string      `json:"product_id"`
+	ProductName string      `json:"product_name"`
+	Quantity    int64       `json:"quantity"`
+	UnitPrice   model.Money `json:"unit_price"`
+	TotalPrice  model.Money `json:"total_price"`
+}
+
+// TransactionListResponse represents a page of transactions, newest first.
+type TransactionListResponse struct {
+	Data       []model.Transaction `json:"data"`
+	NextCursor *string             `json:"next_cursor"`
+}
+
+// ProductTrashResponse represents a page of deleted products, newest first.
+type ProductTrashResponse struct {
+	Data       []ProductItemResponse `json:"data"`
+	NextCursor *string               `json:"next_cursor"`
 }
 
-// ReportResponse represents the daily report response.
+// CategoryTrashResponse represents a page of deleted categories, newest first.
+type CategoryTrashResponse struct {
+	Data       []CategoryItemResponse `json:"data"`
+	NextCursor *string                `json:"next_cursor"`
+}
+
+// PurgeResponse reports what a trash purge removed. KeptProducts counts the deleted
+// products that were kept because transactions refer to them.
+type PurgeResponse struct {
+	Before           string `json:"before"`
+	PurgedProducts   int64  `json:"purged_products"`
+	PurgedCategories int64  `json:"purged_categories"`
+	KeptProducts     int64  `json:"kept_products"`
+}
+
+// AuditListResponse represents a page of audit entries, newest first.
+type AuditListResponse struct {
+	Data       []model.AuditEntry `json:"data"`
+	NextCursor *string            `json:"next_cursor"`
+}
+
+// StockMovementListResponse represents a page of stock movements, newest first.
+type StockMovementListResponse struct {
+	Data       []model.StockMovement `json:"data"`
+	NextCursor *string               `json:"next_cursor"`
+}
+
+// LabelResponse represents a rendered label, or one page of a label sheet.
+type LabelResponse struct {
+	ContentType string
+	Body        []byte
+	Page        int
+	TotalPages  int
+}
+
+// ExportResponse represents a file export. WriteTo streams the file; once it has
+// started, an error means the file was cut short.
+type ExportResponse struct {
+	ContentType string
+	Filename    string
+	WriteTo     func(w io.Writer) error
+}
+
+// ReportResponse represents the sales report of a period. MostPurchasedItem is the
+// first of TopByQuantity, or null without sales.
 type ReportResponse struct {
-	TotalRevenue      float64                    `json:"total_revenue"`
-	TotalTransaction  int64                      `json:"total_transaksi"`
-	MostPurchasedItem *MostPurchasedItemResponse `json:"produk_terlaris"`
+	Range             ReportRangeResponse    `json:"range"`
+	TotalRevenue      model.Money            `json:"total_revenue"`
+	TotalTransaction  int64                  `json:"total_transaksi"`
+	ItemsSold         int64                  `json:"items_sold"`
+	AverageBasket     model.Money            `json:"average_basket"`
+	MostPurchasedItem *ProductSalesResponse  `json:"produk_terlaris"`
+	TopByQuantity     []ProductSalesResponse `json:"top_by_quantity"`
+	TopByRevenue      []ProductSalesResponse `json:"top_by_revenue"`
+}
+
+// ReportRangeResponse represents the period a report covers: the days from StartDate
+// to EndDate in Timezone, which is the half-open range From to To.
+type ReportRangeResponse struct {
+	StartDate string    `json:"start_date"`
+	EndDate   string    `json:"end_date"`
+	Timezone  string    `json:"tz"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+// ProductSalesResponse represents the sales of one product in the report.
+type ProductSalesResponse struct {
+	ProductID   string      `json:"id"`
+	ProductName string      `json:"nama"`
+	Quantity    int64       `json:"qty_terjual"`
+	Revenue     model.Money `json:"revenue"`
+}
+
+// SalesSeriesResponse represents sales bucketed by interval, every bucket of the range
+// present even without sales.
+type SalesSeriesResponse struct {
+	Range    ReportRangeResponse   `json:"range"`
+	Interval string                `json:"interval"`
+	Data     []SalesBucketResponse `json:"data"`
+}
+
+// SalesBucketResponse represents the sales of one bucket, starting at Start.
+type SalesBucketResponse struct {
+	Start            time.Time   `json:"start"`
+	TotalRevenue     model.Money `json:"total_revenue"`
+	TotalTransaction int64       `json:"total_transaksi"`
+	ItemsSold        int64       `json:"items_sold"`
+}
+
+// CategoryReportResponse represents the sales of a period grouped by category.
+type CategoryReportResponse struct {
+	Range        ReportRangeResponse     `json:"range"`
+	TotalRevenue model.Money             `json:"total_revenue"`
+	Data         []CategorySalesResponse `json:"data"`
+}
+
+// CategorySalesResponse represents the sales of one category. ID is null for products
+// without a category. Share is the percentage of the total revenue.
+type CategorySalesResponse struct {
+	ID               *string     `json:"id"`
+	Name             string      `json:"name"`
+	Deleted          bool        `json:"deleted"`
+	TotalRevenue     model.Money `json:"total_revenue"`
+	ItemsSold        int64       `json:"items_sold"`
+	TotalTransaction int64       `json:"total_transaksi"`
+	Share            float64     `json:"share"`
+}
+
+// StoreReportResponse represents the sales of a period across every store, with
+// each store's figures and share of the total revenue.
+type StoreReportResponse struct {
+	Range            ReportRangeResponse  `json:"range"`
+	TotalRevenue     model.Money          `json:"total_revenue"`
+	TotalTransaction int64                `json:"total_transaksi"`
+	ItemsSold        int64                `json:"items_sold"`
+	AverageBasket    model.Money          `json:"average_basket"`
+	Data             []StoreSalesResponse `json:"data"`
+}
+
+// StoreSalesResponse represents the sales of one store. Share is the percentage of
+// the total revenue.
+type StoreSalesResponse struct {
+	ID               string      `json:"id"`
+	Name             string      `json:"name"`
+	TotalRevenue     model.Money `json:"total_revenue"`
+	TotalTransaction int64       `json:"total_transaksi"`
+	ItemsSold        int64       `json:"items_sold"`
+	AverageBasket    model.Money `json:"average_basket"`
+	Share            float64     `json:"share"`
+}
+
+// UserResponse represents a user. Store is null for owners.
+type UserResponse struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Role      string         `json:"role"`
+	Store     *StoreResponse `json:"store"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// UserListResponse represents every user, oldest first.
+type UserListResponse struct {
+	Data []UserResponse `json:"data"`
+}
+
+// APIKeyResponse represents an API key without its secret.
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// APIKeyListResponse represents the API keys of a user, oldest first.
+type APIKeyListResponse struct {
+	Data []APIKeyResponse `json:"data"`
+}
+
+// CreatedAPIKeyResponse represents a newly issued API key. Key is shown only here;
+// the server keeps nothing it could be recovered from.
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// MeResponse represents the caller: its user, the API key it authenticated with,
+// null for the OWNER_API_KEY, and the store the request works in, null when none
+// was resolved.
+type MeResponse struct {
+	User   UserResponse    `json:"user"`
+	APIKey *APIKeyResponse `json:"api_key"`
+	Store  *StoreResponse  `json:"store"`
+}
+
+// StoreResponse represents a store.
+type StoreResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
-// MostPurchasedItemResponse represents the most purchased item in the report.
-type MostPurchasedItemResponse struct {
-	ProductID   string `json:"id"`
-	ProductName string `json:"nama"`
-	Quantity    int64  `json:"qty_terjual"`
+// StoreListResponse represents every store, oldest first.
+type StoreListResponse struct {
+	Data []StoreResponse `json:"data"`
 }
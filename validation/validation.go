@@ -0,0 +1,200 @@
+// Package validation checks request DTOs against rules declared in `validate`
+// struct tags, for example:
+//
+//	Name  string   `json:"name" validate:"required,max=255"`
+//	Stock *int64   `json:"stock" validate:"min=0"`
+//
+// Supported rules:
+//   - required: strings must not be blank, pointers must not be nil and slices must not be empty
+//   - min=N, max=N: bounds on numbers (amounts of money in Rupiah), string length in characters and slice length
+//   - uuid: non-empty strings must be valid UUIDs
+//
+// Rules on a nil pointer other than required are skipped, so optional fields are only
+// checked when sent. Nested structs and slices of structs are validated recursively,
+// and violations are reported with their JSON path, such as "items[1].quantity".
+package validation
+
+import (
+	"fendi/modul-03-task/helper"
+	"fendi/modul-03-task/model"
+	"fendi/modul-03-task/repository"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate checks v, a struct or pointer to a struct, and returns a
+// *repository.ValidationError of kind repository.ErrInvalidRequest listing every
+// violation, or nil when v is valid.
+func Validate(v interface{}) error {
+	var fields []repository.FieldError
+	validateStruct(reflect.Indirect(reflect.ValueOf(v)), "", &fields)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return &repository.ValidationError{Err: repository.ErrInvalidRequest, Fields: fields}
+}
+
+func validateStruct(v reflect.Value, prefix string, fields *[]repository.FieldError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := fieldName(sf)
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		fv := v.Field(i)
+		for _, rule := range parseRules(sf.Tag.Get("validate")) {
+			message, ok := check(rule, fv)
+			if !ok {
+				*fields = append(*fields, repository.FieldError{Field: name, Message: message})
+				// Later rules on the same field usually repeat the first problem.
+				break
+			}
+		}
+
+		validateNested(fv, name, fields)
+	}
+}
+
+// validateNested descends into struct and slice-of-struct fields.
+func validateNested(v reflect.Value, name string, fields *[]repository.FieldError) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		validateStruct(v, name, fields)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			validateNested(v.Index(i), fmt.Sprintf("%s[%d]", name, i), fields)
+		}
+	}
+}
+
+type rule struct {
+	name  string
+	param string
+}
+
+func parseRules(tag string) []rule {
+	var rules []rule
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, param, _ := strings.Cut(part, "=")
+		rules = append(rules, rule{name: name, param: param})
+	}
+
+	return rules
+}
+
+// check applies one rule to v and returns the violation message when it fails.
+func check(r rule, v reflect.Value) (string, bool) {
+	if r.name == "required" {
+		return "is required", !isBlank(v)
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", true
+		}
+		v = v.Elem()
+	}
+
+	switch r.name {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(r.param, 64)
+		if err != nil {
+			panic(fmt.Sprintf("validation: invalid %s parameter %q", r.name, r.param))
+		}
+		return checkBound(r.name, limit, v)
+	case "uuid":
+		if v.Kind() != reflect.String || v.String() == "" {
+			return "", true
+		}
+		return "must be a valid UUID", helper.IsValidUUID(v.String())
+	default:
+		panic(fmt.Sprintf("validation: unknown rule %q", r.name))
+	}
+}
+
+func checkBound(name string, limit float64, v reflect.Value) (string, bool) {
+	var value float64
+	var unit string
+
+	switch v.Kind() {
+	case reflect.Int64:
+		// Amounts are bounded in Rupiah, not in the sen they are stored as.
+		if m, ok := v.Interface().(model.Money); ok {
+			value = m.Float64()
+		} else {
+			value = float64(v.Int())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		value = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = v.Float()
+	case reflect.String:
+		value = float64(utf8.RuneCountInString(v.String()))
+		unit = " characters"
+	case reflect.Slice:
+		value = float64(v.Len())
+		unit = " items"
+	default:
+		return "", true
+	}
+
+	limitStr := strconv.FormatFloat(limit, 'f', -1, 64)
+	if name == "min" {
+		if unit != "" {
+			return "must have at least " + limitStr + unit, value >= limit
+		}
+		return "must be at least " + limitStr, value >= limit
+	}
+
+	if unit != "" {
+		return "must have at most " + limitStr + unit, value <= limit
+	}
+	return "must be at most " + limitStr, value <= limit
+}
+
+func isBlank(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// fieldName returns the JSON name of a struct field.
+func fieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+
+	return name
+}